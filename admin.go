@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type AdminConfig struct {
+	// Token guards the admin API, which is disabled if empty
+	Token string `yaml:"token"`
+	// HTTPPort serves the REST gateway of the admin API if set
+	HTTPPort string `yaml:"httpPort"`
+}
+
+type adminServer struct {
+	pb.UnsafeAdminServer
+	store    PeerStore
+	ipam     *ipam
+	settings *serverSettings
+}
+
+func newAdminServer(store PeerStore, ipam *ipam) *adminServer {
+	return &adminServer{store: store, ipam: ipam, settings: &serverSettings{}}
+}
+
+const adminServicePrefix = "/grpc.Admin/"
+
+// checkAdminToken compares the bearer token in authorization with the admin token
+func checkAdminToken(adminToken, authorization string) error {
+	if adminToken == "" {
+		return status.Error(codes.PermissionDenied, "admin API is disabled")
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return nil
+}
+
+// adminAuthInterceptor rejects the calls to the admin service without the admin token of settings
+func adminAuthInterceptor(settings *serverSettings) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			return handler(ctx, req)
+		}
+		authorization := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				authorization = values[0]
+			}
+		}
+		if err := checkAdminToken(settings.adminToken(), authorization); err != nil {
+			loggerFrom(ctx).warn("reject admin call", "err", err)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func peerToPb(record *PeerRecord) *pb.Peer {
+	return &pb.Peer{
+		Id:                  record.ID,
+		Network:             record.Network,
+		Hostname:            record.Hostname,
+		MacAddress:          record.MacAddress,
+		PublicKey:           record.PublicKey,
+		Address:             record.Address,
+		ListenPort:          record.ListenPort,
+		Endpoint:            record.Endpoint,
+		PublicEndpoint:      record.PublicEndpoint,
+		LastSeen:            record.LastSeen,
+		ClientVersion:       record.ClientVersion,
+		Status:              record.Status,
+		AllowedIPs:          record.AllowedIPs,
+		PersistentKeepalive: record.PersistentKeepalive,
+		PostUp:              record.PostUp,
+		PreDown:             record.PreDown,
+		Tags:                record.Tags,
+		Relay:               record.Relay,
+		RelayedBy:           record.RelayedBy,
+		Routes:              record.Routes,
+		UseGateway:          record.UseGateway,
+		Registered:          record.Token != "",
+		CreatedAt:           record.CreatedAt,
+		UpdatedAt:           record.UpdatedAt,
+	}
+}
+
+// livePeer converts record, telling whether it is online
+func (s *adminServer) livePeer(record *PeerRecord) *pb.Peer {
+	peer := peerToPb(record)
+	peer.Online = s.settings.liveness().online(record, time.Now())
+	return peer
+}
+
+func peerFromPb(peer *pb.Peer) *PeerRecord {
+	return &PeerRecord{
+		ID:                  peer.Id,
+		Network:             strings.TrimSpace(peer.Network),
+		MacAddress:          strings.TrimSpace(peer.MacAddress),
+		Hostname:            strings.TrimSpace(peer.Hostname),
+		PublicKey:           strings.TrimSpace(peer.PublicKey),
+		Address:             strings.TrimSpace(peer.Address),
+		ListenPort:          peer.ListenPort,
+		Endpoint:            strings.TrimSpace(peer.Endpoint),
+		AllowedIPs:          strings.TrimSpace(peer.AllowedIPs),
+		PersistentKeepalive: peer.PersistentKeepalive,
+		PostUp:              peer.PostUp,
+		PreDown:             peer.PreDown,
+		Tags:                strings.TrimSpace(peer.Tags),
+		Relay:               peer.Relay,
+		RelayedBy:           peer.RelayedBy,
+		Routes:              strings.TrimSpace(peer.Routes),
+		UseGateway:          peer.UseGateway,
+	}
+}
+
+// validateCIDRList checks a comma separated list of CIDRs, such as AllowedIPs
+func validateCIDRList(value string) error {
+	for _, cidr := range strings.Split(value, ",") {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePeer(record *PeerRecord) error {
+	if record.Hostname == "" {
+		return errors.New("hostname is required")
+	}
+	if record.PublicKey != "" {
+		if err := validateKey(record.PublicKey); err != nil {
+			return fmt.Errorf("publicKey: %w", err)
+		}
+	}
+	if record.Address != "" {
+		if err := validateCIDRList(record.Address); err != nil {
+			return fmt.Errorf("address: %w", err)
+		}
+	}
+	if record.AllowedIPs != "" {
+		if err := validateCIDRList(record.AllowedIPs); err != nil {
+			return fmt.Errorf("allowedIPs: %w", err)
+		}
+	}
+	if record.Endpoint != "" {
+		if _, _, err := net.SplitHostPort(record.Endpoint); err != nil {
+			return fmt.Errorf("endpoint: %w", err)
+		}
+	}
+	if record.MacAddress != "" {
+		if _, err := net.ParseMAC(record.MacAddress); err != nil {
+			return fmt.Errorf("macAddress: %w", err)
+		}
+	}
+	if record.ListenPort < 0 || record.ListenPort > 65535 {
+		return fmt.Errorf("listenPort: out of range: %d", record.ListenPort)
+	}
+	if record.PersistentKeepalive < 0 || record.PersistentKeepalive > 65535 {
+		return fmt.Errorf("persistentKeepalive: out of range: %d", record.PersistentKeepalive)
+	}
+	if err := validateTags(record.Tags, false); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	if record.Routes != "" {
+		if err := validateCIDRList(record.Routes); err != nil {
+			return fmt.Errorf("routes: %w", err)
+		}
+	}
+	return nil
+}
+
+// storeError maps the errors of the store to gRPC status
+func storeError(err error) error {
+	if errors.Is(err, errNotFound) {
+		return status.Error(codes.NotFound, "peer not found")
+	}
+	logger.error("store failed", "err", err)
+	return status.Error(codes.Internal, err.Error())
+}
+
+// checkHostname makes sure that no other peer than id is named hostname in network
+func (s *adminServer) checkHostname(id int32, network, hostname string) error {
+	other, err := s.store.GetPeerByHostname(network, hostname)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil
+		}
+		return storeError(err)
+	}
+	if other.ID != id {
+		return status.Errorf(codes.AlreadyExists, "hostname %s is used by peer %d in network %s", hostname, other.ID, network)
+	}
+	return nil
+}
+
+func (s *adminServer) CreatePeer(ctx context.Context, req *pb.CreatePeerRequest) (*pb.Peer, error) {
+	if req.Peer == nil {
+		return nil, status.Error(codes.InvalidArgument, "peer is required")
+	}
+	record := peerFromPb(req.Peer)
+	if err := validatePeer(record); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if record.Network == "" {
+		record.Network = defaultNetworkName
+	}
+	if err := s.checkHostname(0, record.Network, record.Hostname); err != nil {
+		return nil, err
+	}
+	if err := s.checkRelay(record); err != nil {
+		return nil, err
+	}
+	record.CreatedAt = time.Now().Unix()
+	record.UpdatedAt = record.CreatedAt
+	if err := s.ipam.createPeer(s.store, record); err != nil {
+		return nil, ipamStatus(err)
+	}
+	loggerFrom(ctx).info("created peer", "peer", record.ID, "hostname", record.Hostname, "network", record.Network)
+	return s.livePeer(record), nil
+}
+
+func (s *adminServer) UpdatePeer(ctx context.Context, req *pb.UpdatePeerRequest) (*pb.Peer, error) {
+	if req.Peer == nil {
+		return nil, status.Error(codes.InvalidArgument, "peer is required")
+	}
+	record := peerFromPb(req.Peer)
+	if err := validatePeer(record); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	current, err := s.store.GetPeer(record.ID)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	if record.Network == "" {
+		record.Network = current.Network
+	}
+	if err = s.checkHostname(record.ID, record.Network, record.Hostname); err != nil {
+		return nil, err
+	}
+	if err = s.checkRelay(record); err != nil {
+		return nil, err
+	}
+	// the public key is set by the client on registration and the address is allocated,
+	// keep them unless replaced explicitly, or the peer moves to another network
+	if record.PublicKey == "" {
+		record.PublicKey = current.PublicKey
+	}
+	// the endpoint is reported by the client as it roams
+	if record.Endpoint == "" {
+		record.Endpoint = current.Endpoint
+	}
+	if record.Address == "" && record.Network == current.Network {
+		record.Address = current.Address
+	}
+	record.UpdatedAt = time.Now().Unix()
+	if err = s.ipam.updatePeer(s.store, record); err != nil {
+		return nil, ipamStatus(err)
+	}
+	loggerFrom(ctx).info("updated peer", "peer", record.ID, "hostname", record.Hostname, "network", record.Network)
+	return s.GetPeer(ctx, &pb.GetPeerRequest{Id: record.ID})
+}
+
+func (s *adminServer) DeletePeer(ctx context.Context, req *pb.DeletePeerRequest) (*pb.DeletePeerResponse, error) {
+	if err := s.checkRelayed(req.Id); err != nil {
+		return nil, err
+	}
+	if err := s.store.DeletePeer(req.Id); err != nil {
+		return nil, storeError(err)
+	}
+	loggerFrom(ctx).info("deleted peer", "peer", req.Id)
+	return &pb.DeletePeerResponse{}, nil
+}
+
+func (s *adminServer) ListPeers(_ context.Context, req *pb.ListPeersRequest) (*pb.ListPeersResponse, error) {
+	records, err := s.store.ListPeers(req.Network)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	resp := &pb.ListPeersResponse{Peers: make([]*pb.Peer, 0, len(records))}
+	for _, record := range records {
+		resp.Peers = append(resp.Peers, s.livePeer(record))
+	}
+	return resp, nil
+}
+
+func (s *adminServer) GetPeer(_ context.Context, req *pb.GetPeerRequest) (*pb.Peer, error) {
+	record, err := s.store.GetPeer(req.Id)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return s.livePeer(record), nil
+}
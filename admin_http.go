@@ -0,0 +1,297 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	peersPath    = "/api/v1/peers"
+	networksPath = "/api/v1/networks"
+	policiesPath = "/api/v1/policies"
+)
+
+// adminGateway serves the admin API as JSON over HTTP:
+//
+//	GET    /api/v1/peers[?network=]     ListPeers
+//	POST   /api/v1/peers                CreatePeer
+//	GET    /api/v1/peers/{id}           GetPeer
+//	PUT    /api/v1/peers/{id}           UpdatePeer
+//	DELETE /api/v1/peers/{id}           DeletePeer
+//	GET    /api/v1/peers/{id}/stats     GetPeerStats
+//	GET    /api/v1/networks             ListNetworks
+//	POST   /api/v1/networks             CreateNetwork
+//	GET    /api/v1/networks/{name}      GetNetwork
+//	PUT    /api/v1/networks/{name}      UpdateNetwork
+//	DELETE /api/v1/networks/{name}      DeleteNetwork
+//	GET    /api/v1/policies[?network=]  ListPolicies
+//	POST   /api/v1/policies             CreatePolicy
+//	GET    /api/v1/policies/{id}        GetPolicy
+//	PUT    /api/v1/policies/{id}        UpdatePolicy
+//	DELETE /api/v1/policies/{id}        DeletePolicy
+type adminGateway struct {
+	admin *adminServer
+}
+
+func newAdminGateway(admin *adminServer) http.Handler {
+	mux := http.NewServeMux()
+	gateway := &adminGateway{admin: admin}
+	mux.HandleFunc(peersPath, gateway.handlePeers)
+	mux.HandleFunc(peersPath+"/", gateway.handlePeer)
+	mux.HandleFunc(networksPath, gateway.handleNetworks)
+	mux.HandleFunc(networksPath+"/", gateway.handleNetwork)
+	mux.HandleFunc(policiesPath, gateway.handlePolicies)
+	mux.HandleFunc(policiesPath+"/", gateway.handlePolicy)
+	return httpRequestLogger(mux)
+}
+
+// httpStatus maps gRPC codes to HTTP status codes
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.Unimplemented:
+		return http.StatusMethodNotAllowed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, msg proto.Message, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		st := status.Convert(err)
+		w.WriteHeader(httpStatus(st.Code()))
+		msg = st.Proto()
+	}
+	content, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		logger.error("encode the response", "err", err)
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func readJSON(r *http.Request, msg proto.Message) error {
+	content, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = protojson.Unmarshal(content, msg); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+func readPeer(r *http.Request) (*pb.Peer, error) {
+	peer := &pb.Peer{}
+	return peer, readJSON(r, peer)
+}
+
+func readNetwork(r *http.Request) (*pb.Network, error) {
+	network := &pb.Network{}
+	return network, readJSON(r, network)
+}
+
+func readPolicy(r *http.Request) (*pb.Policy, error) {
+	policy := &pb.Policy{}
+	return policy, readJSON(r, policy)
+}
+
+func (g *adminGateway) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if err := checkAdminToken(g.admin.settings.adminToken(), r.Header.Get("Authorization")); err != nil {
+		loggerFrom(r.Context()).warn("reject admin request", "err", err)
+		writeJSON(w, nil, err)
+		return false
+	}
+	return true
+}
+
+func (g *adminGateway) handlePeers(w http.ResponseWriter, r *http.Request) {
+	if !g.authorize(w, r) {
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.admin.ListPeers(ctx, &pb.ListPeersRequest{Network: r.URL.Query().Get("network")})
+		writeJSON(w, resp, err)
+	case http.MethodPost:
+		peer, err := readPeer(r)
+		if err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		resp, err := g.admin.CreatePeer(ctx, &pb.CreatePeerRequest{Peer: peer})
+		writeJSON(w, resp, err)
+	default:
+		writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+	}
+}
+
+func (g *adminGateway) handlePeer(w http.ResponseWriter, r *http.Request) {
+	if !g.authorize(w, r) {
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, peersPath+"/")
+	stats := strings.HasSuffix(path, "/stats")
+	path = strings.TrimSuffix(path, "/stats")
+	id, err := strconv.ParseInt(path, 10, 32)
+	if err != nil {
+		writeJSON(w, nil, status.Error(codes.NotFound, "invalid peer id"))
+		return
+	}
+	ctx := r.Context()
+	if stats {
+		if r.Method != http.MethodGet {
+			writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+			return
+		}
+		resp, err := g.admin.GetPeerStats(ctx, &pb.GetPeerStatsRequest{Id: int32(id)})
+		writeJSON(w, resp, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.admin.GetPeer(ctx, &pb.GetPeerRequest{Id: int32(id)})
+		writeJSON(w, resp, err)
+	case http.MethodPut:
+		peer, err := readPeer(r)
+		if err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		peer.Id = int32(id)
+		resp, err := g.admin.UpdatePeer(ctx, &pb.UpdatePeerRequest{Peer: peer})
+		writeJSON(w, resp, err)
+	case http.MethodDelete:
+		resp, err := g.admin.DeletePeer(ctx, &pb.DeletePeerRequest{Id: int32(id)})
+		writeJSON(w, resp, err)
+	default:
+		writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+	}
+}
+
+func (g *adminGateway) handleNetworks(w http.ResponseWriter, r *http.Request) {
+	if !g.authorize(w, r) {
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.admin.ListNetworks(ctx, &pb.ListNetworksRequest{})
+		writeJSON(w, resp, err)
+	case http.MethodPost:
+		network, err := readNetwork(r)
+		if err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		resp, err := g.admin.CreateNetwork(ctx, &pb.CreateNetworkRequest{Network: network})
+		writeJSON(w, resp, err)
+	default:
+		writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+	}
+}
+
+func (g *adminGateway) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	if !g.authorize(w, r) {
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, networksPath+"/")
+	if name == "" {
+		writeJSON(w, nil, status.Error(codes.NotFound, "invalid network name"))
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.admin.GetNetwork(ctx, &pb.GetNetworkRequest{Name: name})
+		writeJSON(w, resp, err)
+	case http.MethodPut:
+		network, err := readNetwork(r)
+		if err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		network.Name = name
+		resp, err := g.admin.UpdateNetwork(ctx, &pb.UpdateNetworkRequest{Network: network})
+		writeJSON(w, resp, err)
+	case http.MethodDelete:
+		resp, err := g.admin.DeleteNetwork(ctx, &pb.DeleteNetworkRequest{Name: name})
+		writeJSON(w, resp, err)
+	default:
+		writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+	}
+}
+
+func (g *adminGateway) handlePolicies(w http.ResponseWriter, r *http.Request) {
+	if !g.authorize(w, r) {
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.admin.ListPolicies(ctx, &pb.ListPoliciesRequest{Network: r.URL.Query().Get("network")})
+		writeJSON(w, resp, err)
+	case http.MethodPost:
+		policy, err := readPolicy(r)
+		if err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		resp, err := g.admin.CreatePolicy(ctx, &pb.CreatePolicyRequest{Policy: policy})
+		writeJSON(w, resp, err)
+	default:
+		writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+	}
+}
+
+func (g *adminGateway) handlePolicy(w http.ResponseWriter, r *http.Request) {
+	if !g.authorize(w, r) {
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, policiesPath+"/"), 10, 32)
+	if err != nil {
+		writeJSON(w, nil, status.Error(codes.NotFound, "invalid policy id"))
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.admin.GetPolicy(ctx, &pb.GetPolicyRequest{Id: int32(id)})
+		writeJSON(w, resp, err)
+	case http.MethodPut:
+		policy, err := readPolicy(r)
+		if err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		policy.Id = int32(id)
+		resp, err := g.admin.UpdatePolicy(ctx, &pb.UpdatePolicyRequest{Policy: policy})
+		writeJSON(w, resp, err)
+	case http.MethodDelete:
+		resp, err := g.admin.DeletePolicy(ctx, &pb.DeletePolicyRequest{Id: int32(id)})
+		writeJSON(w, resp, err)
+	default:
+		writeJSON(w, nil, status.Errorf(codes.Unimplemented, "method %s not allowed", r.Method))
+	}
+}
@@ -0,0 +1,809 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var errUsage = errors.New("invalid usage")
+
+func usageErrorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// flagSet is a flag.FlagSet whose flags may fall back to environment variables
+type flagSet struct {
+	*flag.FlagSet
+	envs map[string]string // flag name -> environment variable
+}
+
+func newFlagSet(name, args, description string) *flagSet {
+	fs := &flagSet{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError), envs: make(map[string]string)}
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: wukuard %s [flags] %s\n\n%s\n\nFlags:\n", name, args, description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// env makes the flag name fall back to the environment variable env
+func (fs *flagSet) env(name, env string) {
+	fs.envs[name] = env
+	f := fs.Lookup(name)
+	f.Usage = fmt.Sprintf("%s (env %s)", f.Usage, env)
+}
+
+// parse parses args, then sets the flags missing in args from their environment variables
+func (fs *flagSet) parse(args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for name, env := range fs.envs {
+		value, ok := os.LookupEnv(env)
+		if set[name] || !ok {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return usageErrorf("invalid %s: %s", env, err.Error())
+		}
+	}
+	return nil
+}
+
+func (fs *flagSet) isSet(name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	if env, ok := fs.envs[name]; ok && !set {
+		_, set = os.LookupEnv(env)
+	}
+	return set
+}
+
+// overlay calls load, then sets again the flags given in args or environment variables,
+// so that they take precedence over what load has set
+func (fs *flagSet) overlay(load func() error) error {
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if fs.isSet(f.Name) {
+			values[f.Name] = f.Value.String()
+		}
+	})
+	if err := load(); err != nil {
+		return err
+	}
+	for name, value := range values {
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subcommand splits args into the subcommand and its args, def being used if there is none
+func subcommand(args []string, def string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return strings.ToLower(args[0]), args[1:]
+	}
+	return def, args
+}
+
+func tlsFlags(fs *flagSet, conf *ClientTLSConfig) {
+	fs.StringVar(&conf.CA, "tls-ca", "", "the only CA trusted to sign the certificate of the server")
+	fs.StringVar(&conf.Cert, "tls-cert", "", "the client certificate, for mutual TLS")
+	fs.StringVar(&conf.Key, "tls-key", "", "the key of the client certificate")
+	fs.StringVar(&conf.ServerName, "tls-server-name", "", "the name checked in the certificate of the server")
+	fs.env("tls-ca", "WUKUARD_TLS_CA")
+	fs.env("tls-cert", "WUKUARD_TLS_CERT")
+	fs.env("tls-key", "WUKUARD_TLS_KEY")
+	fs.env("tls-server-name", "WUKUARD_TLS_SERVER_NAME")
+}
+
+func serverCommand(args []string) error {
+	descriptions := map[string]string{
+		"run":          "Run the server.",
+		"migrate":      "Apply the schema migrations of the store and exit.",
+		"migrate-keys": "Move the private keys stored by older versions out of the store, one <hostname>.key file per peer.",
+		"token":        "Mint a one-time join token for the peer named hostname in network, created on registration if needed.",
+	}
+	if len(args) > 0 && isLegacyServerConfig(args, descriptions) {
+		// wukuard server <config>
+		args = append([]string{"run", "-config", args[0]}, args[1:]...)
+	}
+	name, args := subcommand(args, "run")
+	description, ok := descriptions[name]
+	if !ok {
+		return usageErrorf("unknown server command %q, expect run, migrate, migrate-keys or token", name)
+	}
+	fs := newFlagSet("server "+name, "", description)
+	confPath := fs.String("config", "/etc/config.yaml", "the config file of the server")
+	fs.env("config", "WUKUARD_CONFIG")
+	var (
+		outDir   *string
+		network  *string
+		hostname *string
+		ttl      *time.Duration
+	)
+	switch name {
+	case "migrate-keys":
+		outDir = fs.String("out", "keys", "the directory to write the keys to")
+	case "token":
+		network = fs.String("network", defaultNetworkName, "the network the peer joins")
+		hostname = fs.String("hostname", "", "the hostname of the peer")
+		ttl = fs.Duration("ttl", defaultJoinTokenTTL, "how long the token is valid")
+	}
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	switch name {
+	case "migrate":
+		return migrateMain(*confPath)
+	case "migrate-keys":
+		return migrateKeysMain(*confPath, *outDir)
+	case "token":
+		if *hostname == "" {
+			return usageErrorf("-hostname is required")
+		}
+		return tokenMain(*confPath, *network, *hostname, *ttl)
+	default:
+		return serverMain(*confPath)
+	}
+}
+
+// clientFlags binds the flags shared by the commands running on the client, returning the path of its config file
+func clientFlags(fs *flagSet, conf *ClientConfig) *string {
+	confPath := fs.String("config", "", "the config file of the client, overridden by flags")
+	fs.env("config", "WUKUARD_CLIENT_CONFIG")
+	fs.StringVar(&conf.Network, "network", "", "the network to join, the default one of the server if empty")
+	fs.StringVar(&conf.Interface, "wg-interface", conf.Interface, "the name of the WireGuard interface")
+	fs.StringVar(&conf.ConfigDir, "config-dir", conf.ConfigDir, "where the key, the credential and the wg-quick config are kept")
+	fs.StringVar(&conf.Backend, "backend", conf.Backend, "how to configure WireGuard: netlink or wg-quick")
+	fs.env("network", "WUKUARD_NETWORK")
+	fs.env("wg-interface", "WUKUARD_WG_INTERFACE")
+	fs.env("config-dir", "WUKUARD_CONFIG_DIR")
+	fs.env("backend", "WUKUARD_BACKEND")
+	return confPath
+}
+
+// parseClientFlags parses args, with the config file of the client under the flags
+func parseClientFlags(fs *flagSet, args []string, conf *ClientConfig, confPath *string) error {
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if *confPath == "" {
+		return nil
+	}
+	return fs.overlay(func() error {
+		return loadClientConfig(*confPath, conf)
+	})
+}
+
+func clientCommand(args []string) error {
+	conf, err := parseClientCommand(args)
+	if err != nil {
+		return err
+	}
+	// SIGHUP reads the flags, the environment and the config file again
+	return clientMain(conf, func() (*ClientConfig, error) {
+		return parseClientCommand(args)
+	})
+}
+
+// parseClientCommand returns the config of the client from args, the environment and the config file
+func parseClientCommand(args []string) (*ClientConfig, error) {
+	conf := defaultClientConfig()
+	fs := newFlagSet("client", "[server-addr [nic]]", "Run the client, keeping the WireGuard interface in sync with the server.")
+	fs.StringVar(&conf.Server, "server", "", "the address of the server, host:port")
+	fs.StringVar(&conf.NIC, "nic", "", "the network interface whose MAC address is reported to the server")
+	fs.IntVar(&conf.ListenPort, "listen-port", conf.ListenPort, "the port advertised in the endpoint of the client until the server configures the interface")
+	fs.StringVar(&conf.StunServer, "stun-server", "", "the host:port of a STUN server to learn the public address of the client from")
+	fs.DurationVar(&conf.Interval, "interval", conf.Interval, "how often to poll the server while the network stream is broken")
+	fs.StringVar(&conf.JoinToken, "join-token", "", "the one-time join token, only needed for the first run")
+	fs.StringVar(&conf.MetricsAddr, "metrics-addr", "", "the host:port to expose the metrics of the client on")
+	fs.StringVar(&conf.Log.Level, "log-level", "", "the lowest level logged: debug, info, warn or error")
+	fs.StringVar(&conf.Log.Format, "log-format", "", "the format of the logs: text or json")
+	fs.BoolVar(&conf.KeepInterface, "keep-interface", false, "leave the WireGuard interfaces up when the client stops")
+	fs.env("server", "WUKUARD_SERVER_ADDR")
+	fs.env("nic", "WUKUARD_NIC")
+	fs.env("listen-port", "WUKUARD_LISTEN_PORT")
+	fs.env("stun-server", "WUKUARD_STUN_SERVER")
+	fs.env("interval", "WUKUARD_INTERVAL")
+	fs.env("join-token", "WUKUARD_JOIN_TOKEN")
+	fs.env("metrics-addr", "WUKUARD_METRICS_ADDR")
+	fs.env("log-level", "WUKUARD_LOG_LEVEL")
+	fs.env("log-format", "WUKUARD_LOG_FORMAT")
+	fs.env("keep-interface", "WUKUARD_KEEP_INTERFACE")
+	confPath := clientFlags(fs, conf)
+	tlsFlags(fs, &conf.TLS)
+	if err := parseClientFlags(fs, args, conf, confPath); err != nil {
+		return nil, err
+	}
+	// WUKUARD_NIC was WUKUARD_INTERFACE, too easily taken for WUKUARD_WG_INTERFACE
+	if nic, ok := os.LookupEnv("WUKUARD_INTERFACE"); ok && !fs.isSet("nic") && conf.NIC == "" {
+		logger.warn("WUKUARD_INTERFACE is deprecated, set WUKUARD_NIC instead")
+		conf.NIC = nic
+	}
+	// wukuard client <server-addr> [nic]
+	if fs.NArg() > 0 {
+		conf.Server = fs.Arg(0)
+	}
+	if fs.NArg() > 1 {
+		conf.NIC = fs.Arg(1)
+	}
+	if conf.Server == "" {
+		return nil, usageErrorf("-server is required, in flags or the config file")
+	}
+	if conf.Interval <= 0 {
+		return nil, usageErrorf("-interval must be positive")
+	}
+	return conf, nil
+}
+
+// isLegacyServerConfig tells whether args are those of older versions, the config file as the only argument:
+// any existing file, or a single argument which isn't a command
+func isLegacyServerConfig(args []string, commands map[string]string) bool {
+	if strings.HasPrefix(args[0], "-") {
+		return false
+	}
+	if _, ok := commands[strings.ToLower(args[0])]; ok {
+		return false
+	}
+	if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+		return true
+	}
+	return len(args) == 1
+}
+
+// adminClient dials the admin API of the server with the flags of fs
+type adminClient struct {
+	server  string
+	token   string
+	tlsConf ClientTLSConfig
+}
+
+func adminFlags(fs *flagSet) *adminClient {
+	client := &adminClient{}
+	fs.StringVar(&client.server, "server", "", "the address of the server, host:port")
+	fs.StringVar(&client.token, "admin-token", "", "the admin token of the server")
+	fs.env("server", "WUKUARD_SERVER_ADDR")
+	fs.env("admin-token", "WUKUARD_ADMIN_TOKEN")
+	tlsFlags(fs, &client.tlsConf)
+	return client
+}
+
+// adminToken sends the admin token along every call
+type adminToken string
+
+func (t adminToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity lets the token go in cleartext to the loopback only, see isLoopback
+func (t adminToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// isLoopback tells whether the server at addr, host:port, is on this host
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// call dials the server and runs f with a timeout
+func (client *adminClient) call(f func(ctx context.Context, c pb.AdminClient) error) error {
+	if client.server == "" {
+		return usageErrorf("-server is required")
+	}
+	if !client.tlsConf.enabled() && !isLoopback(client.server) {
+		return usageErrorf("the admin token would be sent in cleartext to %s, set -tls-ca or -tls-server-name", client.server)
+	}
+	transportOption, err := client.tlsConf.dialOption()
+	if err != nil {
+		return fmt.Errorf("load TLS config: %w", err)
+	}
+	conn, err := grpc.Dial(client.server, transportOption, grpc.WithPerRPCCredentials(adminToken(client.token)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return f(ctx, pb.NewAdminClient(conn))
+}
+
+func printJSON(msg proto.Message) error {
+	content, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(content))
+	return nil
+}
+
+// peerFlags binds the fields of a peer to fs
+func peerFlags(fs *flagSet, peer *pb.Peer) {
+	fs.StringVar(&peer.Network, "network", "", "the network of the peer, the default one if empty")
+	fs.StringVar(&peer.Hostname, "hostname", "", "the hostname of the peer")
+	fs.StringVar(&peer.MacAddress, "mac", "", "the MAC address of the peer")
+	fs.StringVar(&peer.PublicKey, "public-key", "", "the public key of the peer, set by the client on registration")
+	fs.StringVar(&peer.Address, "address", "", "the addresses of the interface, allocated if empty")
+	fs.StringVar(&peer.AllowedIPs, "allowed-ips", "", "the AllowedIPs of the peer, derived from the address if empty")
+	fs.StringVar(&peer.Endpoint, "endpoint", "", "the endpoint of the peer, reported by the client")
+	fs.StringVar(&peer.PostUp, "post-up", "", "run after the interface is created")
+	fs.StringVar(&peer.PreDown, "pre-down", "", "run before the interface is removed")
+	int32Flag(fs, &peer.ListenPort, "listen-port", "the port the interface listens on, the one of the network if empty")
+	int32Flag(fs, &peer.PersistentKeepalive, "keepalive", "the PersistentKeepalive of the peer in seconds, the one of the network if empty")
+	fs.StringVar(&peer.Tags, "tags", "", "the comma separated tags of the peer, matched by the policies")
+	fs.BoolVar(&peer.Relay, "relay", false, "let the peer forward the traffic of the peers it relays")
+	int32Flag(fs, &peer.RelayedBy, "relayed-by", "the id of the relay all the traffic of the peer goes through, 0 for none")
+	fs.StringVar(&peer.Routes, "routes", "", "the comma separated subnets, such as LANs or 0.0.0.0/0, the peer advertises as a gateway")
+	fs.BoolVar(&peer.UseGateway, "use-gateway", false, "send the traffic to the internet through the default route of the network")
+}
+
+// formatUnix formats unix seconds, 0 being never
+func formatUnix(seconds int64) string {
+	if seconds <= 0 {
+		return "never"
+	}
+	return time.Unix(seconds, 0).Format(time.RFC3339)
+}
+
+func int32Flag(fs *flagSet, p *int32, name, usage string) {
+	fs.Func(name, usage, func(value string) error {
+		var n int32
+		_, err := fmt.Sscan(value, &n)
+		*p = n
+		return err
+	})
+}
+
+// mergePeer overrides the fields of current set in fs by peer
+func mergePeer(fs *flagSet, current, peer *pb.Peer) {
+	if fs.isSet("network") && current.Network != peer.Network {
+		// allocate the address in the new network
+		current.Address, current.AllowedIPs = "", ""
+	} else if fs.isSet("address") && current.Address != peer.Address &&
+		normalizeCIDRList(current.AllowedIPs) == normalizeCIDRList(derivedAllowedIPs(current.Address)) {
+		// derive them again from the new address, unless set by the admin
+		current.AllowedIPs = ""
+	}
+	fields := map[string]func(){
+		"network":     func() { current.Network = peer.Network },
+		"hostname":    func() { current.Hostname = peer.Hostname },
+		"mac":         func() { current.MacAddress = peer.MacAddress },
+		"public-key":  func() { current.PublicKey = peer.PublicKey },
+		"address":     func() { current.Address = peer.Address },
+		"allowed-ips": func() { current.AllowedIPs = peer.AllowedIPs },
+		"endpoint":    func() { current.Endpoint = peer.Endpoint },
+		"post-up":     func() { current.PostUp = peer.PostUp },
+		"pre-down":    func() { current.PreDown = peer.PreDown },
+		"listen-port": func() { current.ListenPort = peer.ListenPort },
+		"keepalive":   func() { current.PersistentKeepalive = peer.PersistentKeepalive },
+		"tags":        func() { current.Tags = peer.Tags },
+		"relay":       func() { current.Relay = peer.Relay },
+		"relayed-by":  func() { current.RelayedBy = peer.RelayedBy },
+		"routes":      func() { current.Routes = peer.Routes },
+		"use-gateway": func() { current.UseGateway = peer.UseGateway },
+	}
+	for name, set := range fields {
+		if fs.isSet(name) {
+			set()
+		}
+	}
+}
+
+func peerCommand(args []string) error {
+	name, args := subcommand(args, "list")
+	descriptions := map[string]string{
+		"list":   "List the peers.",
+		"get":    "Show the peer with id.",
+		"create": "Create a peer.",
+		"update": "Update the fields given in flags of the peer with id.",
+		"delete": "Delete the peer with id.",
+		"stats":  "Show the state of the tunnels of the peer with id, as last reported by its client.",
+	}
+	description, ok := descriptions[name]
+	if !ok {
+		return usageErrorf("unknown peer command %q, expect list, get, create, update, delete or stats", name)
+	}
+	fs := newFlagSet("peer "+name, "", description)
+	client := adminFlags(fs)
+	var (
+		id      int
+		network string
+	)
+	if name == "get" || name == "update" || name == "delete" || name == "stats" {
+		fs.IntVar(&id, "id", 0, "the id of the peer")
+	}
+	if name == "list" {
+		fs.StringVar(&network, "network", "", "only list the peers of this network")
+	}
+	peer := &pb.Peer{}
+	if name == "create" || name == "update" {
+		peerFlags(fs, peer)
+	}
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if (name == "get" || name == "update" || name == "delete" || name == "stats") && id <= 0 {
+		return usageErrorf("-id is required")
+	}
+
+	return client.call(func(ctx context.Context, c pb.AdminClient) error {
+		switch name {
+		case "stats":
+			resp, err := c.GetPeerStats(ctx, &pb.GetPeerStatsRequest{Id: int32(id)})
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "PEER\tHOSTNAME\tENDPOINT\tLATEST HANDSHAKE\tRX\tTX\tREPORTED")
+			for _, stats := range resp.Stats {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%s\n", stats.PeerId, stats.Hostname, stats.Endpoint,
+					formatUnix(stats.LastHandshake), stats.RxBytes, stats.TxBytes, formatUnix(stats.ReportedAt))
+			}
+			return w.Flush()
+		case "get":
+			resp, err := c.GetPeer(ctx, &pb.GetPeerRequest{Id: int32(id)})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "create":
+			resp, err := c.CreatePeer(ctx, &pb.CreatePeerRequest{Peer: peer})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "update":
+			current, err := c.GetPeer(ctx, &pb.GetPeerRequest{Id: int32(id)})
+			if err != nil {
+				return err
+			}
+			mergePeer(fs, current, peer)
+			resp, err := c.UpdatePeer(ctx, &pb.UpdatePeerRequest{Peer: current})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "delete":
+			_, err := c.DeletePeer(ctx, &pb.DeletePeerRequest{Id: int32(id)})
+			return err
+		default:
+			resp, err := c.ListPeers(ctx, &pb.ListPeersRequest{Network: network})
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNETWORK\tHOSTNAME\tADDRESS\tALLOWED IPS\tENDPOINT\tTAGS\tREGISTERED\tONLINE\tLAST SEEN\tVERSION")
+			for _, peer := range resp.Peers {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%v\t%v\t%s\t%s\n",
+					peer.Id, peer.Network, peer.Hostname, peer.Address, peer.AllowedIPs, peer.Endpoint, peer.Tags, peer.Registered,
+					peer.Online, formatUnix(peer.LastSeen), peer.ClientVersion)
+			}
+			return w.Flush()
+		}
+	})
+}
+
+// networkFlags binds the fields of a network but its name to fs
+func networkFlags(fs *flagSet, network *pb.Network) {
+	fs.StringVar(&network.Cidr, "cidr", "", "the IPv4 network to allocate the addresses of the peers from")
+	fs.StringVar(&network.Cidr6, "cidr6", "", "the IPv6 network to allocate the addresses of the peers from")
+	fs.StringVar(&network.Dns, "dns", "", "the comma separated DNS servers of the interfaces")
+	int32Flag(fs, &network.ListenPort, "listen-port", "the listen port of the new peers")
+	int32Flag(fs, &network.PersistentKeepalive, "keepalive", "the PersistentKeepalive of the new peers, in seconds")
+}
+
+// mergeNetwork overrides the fields of current set in fs by network
+func mergeNetwork(fs *flagSet, current, network *pb.Network) {
+	fields := map[string]func(){
+		"cidr":        func() { current.Cidr = network.Cidr },
+		"cidr6":       func() { current.Cidr6 = network.Cidr6 },
+		"dns":         func() { current.Dns = network.Dns },
+		"listen-port": func() { current.ListenPort = network.ListenPort },
+		"keepalive":   func() { current.PersistentKeepalive = network.PersistentKeepalive },
+	}
+	for name, set := range fields {
+		if fs.isSet(name) {
+			set()
+		}
+	}
+}
+
+func networkCommand(args []string) error {
+	name, args := subcommand(args, "list")
+	descriptions := map[string]string{
+		"list":   "List the networks.",
+		"show":   "Show the network with name, the default one if empty.",
+		"create": "Create a network.",
+		"update": "Update the fields given in flags of the network with name.",
+		"delete": "Delete the network with name, which must have no peer.",
+	}
+	description, ok := descriptions[name]
+	if !ok {
+		return usageErrorf("unknown network command %q, expect list, show, create, update or delete", name)
+	}
+	fs := newFlagSet("network "+name, "", description)
+	client := adminFlags(fs)
+	network := &pb.Network{}
+	if name != "list" {
+		fs.StringVar(&network.Name, "name", "", "the name of the network")
+	}
+	if name == "create" || name == "update" {
+		networkFlags(fs, network)
+	}
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if (name == "create" || name == "update" || name == "delete") && network.Name == "" {
+		return usageErrorf("-name is required")
+	}
+
+	return client.call(func(ctx context.Context, c pb.AdminClient) error {
+		switch name {
+		case "show":
+			resp, err := c.GetNetwork(ctx, &pb.GetNetworkRequest{Name: network.Name})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "create":
+			resp, err := c.CreateNetwork(ctx, &pb.CreateNetworkRequest{Network: network})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "update":
+			current, err := c.GetNetwork(ctx, &pb.GetNetworkRequest{Name: network.Name})
+			if err != nil {
+				return err
+			}
+			mergeNetwork(fs, current, network)
+			resp, err := c.UpdateNetwork(ctx, &pb.UpdateNetworkRequest{Network: current})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "delete":
+			_, err := c.DeleteNetwork(ctx, &pb.DeleteNetworkRequest{Name: network.Name})
+			return err
+		default:
+			resp, err := c.ListNetworks(ctx, &pb.ListNetworksRequest{})
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCIDR\tCIDR6\tDNS\tPEERS")
+			for _, network := range resp.Networks {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n",
+					network.Name, network.Cidr, network.Cidr6, network.Dns, network.PeerCount)
+			}
+			return w.Flush()
+		}
+	})
+}
+
+// policyFlags binds the fields of a policy but its id to fs
+func policyFlags(fs *flagSet, policy *pb.Policy) {
+	fs.StringVar(&policy.Network, "network", "", "the network of the policy, the default one if empty")
+	fs.StringVar(&policy.Description, "description", "", "what the policy is for")
+	fs.StringVar(&policy.From, "from", "", "the comma separated tags of the peers allowed to connect, * for every peer")
+	fs.StringVar(&policy.To, "to", "", "the comma separated tags of the peers they may connect to, * for every peer")
+	fs.StringVar(&policy.Cidrs, "cidrs", "", "only allow these comma separated CIDRs of the peers of -to, all their AllowedIPs if empty")
+}
+
+// mergePolicy overrides the fields of current set in fs by policy
+func mergePolicy(fs *flagSet, current, policy *pb.Policy) {
+	fields := map[string]func(){
+		"network":     func() { current.Network = policy.Network },
+		"description": func() { current.Description = policy.Description },
+		"from":        func() { current.From = policy.From },
+		"to":          func() { current.To = policy.To },
+		"cidrs":       func() { current.Cidrs = policy.Cidrs },
+	}
+	for name, set := range fields {
+		if fs.isSet(name) {
+			set()
+		}
+	}
+}
+
+func policyCommand(args []string) error {
+	name, args := subcommand(args, "list")
+	descriptions := map[string]string{
+		"list":   "List the policies.",
+		"get":    "Show the policy with id.",
+		"create": "Create a policy.",
+		"update": "Update the fields given in flags of the policy with id.",
+		"delete": "Delete the policy with id.",
+	}
+	description, ok := descriptions[name]
+	if !ok {
+		return usageErrorf("unknown policy command %q, expect list, get, create, update or delete", name)
+	}
+	fs := newFlagSet("policy "+name, "", description)
+	client := adminFlags(fs)
+	var (
+		id      int
+		network string
+	)
+	if name == "get" || name == "update" || name == "delete" {
+		fs.IntVar(&id, "id", 0, "the id of the policy")
+	}
+	if name == "list" {
+		fs.StringVar(&network, "network", "", "only list the policies of this network")
+	}
+	policy := &pb.Policy{}
+	if name == "create" || name == "update" {
+		policyFlags(fs, policy)
+	}
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	if (name == "get" || name == "update" || name == "delete") && id <= 0 {
+		return usageErrorf("-id is required")
+	}
+	if name == "create" && (policy.From == "" || policy.To == "") {
+		return usageErrorf("-from and -to are required")
+	}
+
+	return client.call(func(ctx context.Context, c pb.AdminClient) error {
+		switch name {
+		case "get":
+			resp, err := c.GetPolicy(ctx, &pb.GetPolicyRequest{Id: int32(id)})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "create":
+			resp, err := c.CreatePolicy(ctx, &pb.CreatePolicyRequest{Policy: policy})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "update":
+			current, err := c.GetPolicy(ctx, &pb.GetPolicyRequest{Id: int32(id)})
+			if err != nil {
+				return err
+			}
+			mergePolicy(fs, current, policy)
+			resp, err := c.UpdatePolicy(ctx, &pb.UpdatePolicyRequest{Policy: current})
+			if err != nil {
+				return err
+			}
+			return printJSON(resp)
+		case "delete":
+			_, err := c.DeletePolicy(ctx, &pb.DeletePolicyRequest{Id: int32(id)})
+			return err
+		default:
+			resp, err := c.ListPolicies(ctx, &pb.ListPoliciesRequest{Network: network})
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNETWORK\tFROM\tTO\tCIDRS\tDESCRIPTION")
+			for _, policy := range resp.Policies {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+					policy.Id, policy.Network, policy.From, policy.To, policy.Cidrs, policy.Description)
+			}
+			return w.Flush()
+		}
+	})
+}
+
+func statusCommand(args []string) error {
+	conf := defaultClientConfig()
+	fs := newFlagSet("status", "", "Show the local state of the client: its key, its registration and its WireGuard interface, for every network.")
+	confPath := clientFlags(fs, conf)
+	if err := parseClientFlags(fs, args, conf, confPath); err != nil {
+		return err
+	}
+	confs, err := conf.networkConfigs()
+	if err != nil {
+		return err
+	}
+	for i, networkConf := range confs {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err = printStatus(networkConf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printStatus(conf *ClientConfig) error {
+	network := conf.Network
+	if network == "" {
+		network = defaultNetworkName
+	}
+	fmt.Printf("network: %s\n", network)
+	fmt.Printf("interface: %s\n", conf.Interface)
+	if privateKey, err := readFile(conf.privateKeyFilename()); err == nil {
+		publicKey, err := publicKeyOf(privateKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", conf.privateKeyFilename(), err)
+		}
+		fmt.Printf("public key: %s\n", publicKey)
+	} else {
+		fmt.Println("public key: none")
+	}
+	_, err := os.Stat(conf.credentialFilename())
+	fmt.Printf("registered: %v\n", err == nil)
+
+	backend, err := newWireGuardBackend(conf)
+	if err != nil {
+		return err
+	}
+	current, err := backend.Current()
+	if err != nil {
+		return fmt.Errorf("read the interface: %w", err)
+	}
+	if current == nil || current.interfaceConf == nil {
+		fmt.Println("state: down")
+		return nil
+	}
+	fmt.Println("state: up")
+	fmt.Printf("address: %s\n", current.interfaceConf.Address)
+	fmt.Printf("listen port: %d\n", current.interfaceConf.ListenPort)
+	stats, err := backend.Stats()
+	if err != nil {
+		return fmt.Errorf("read the stats: %w", err)
+	}
+	statsByKey := make(map[string]*PeerStats, len(stats))
+	for _, peerStats := range stats {
+		statsByKey[peerStats.PublicKey] = peerStats
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PEER\tENDPOINT\tALLOWED IPS\tLATEST HANDSHAKE\tRX\tTX")
+	for _, peer := range current.peerConfList {
+		handshake, rx, tx := "never", int64(0), int64(0)
+		if peerStats, ok := statsByKey[peer.PublicKey]; ok {
+			if !peerStats.LastHandshake.IsZero() {
+				handshake = peerStats.LastHandshake.Format(time.RFC3339)
+			}
+			rx, tx = peerStats.RxBytes, peerStats.TxBytes
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", peer.PublicKey, peer.Endpoint, peer.AllowedIPs, handshake, rx, tx)
+	}
+	return w.Flush()
+}
+
+func versionCommand(args []string) error {
+	fs := newFlagSet("version", "", "Print the version.")
+	if err := fs.parse(args); err != nil {
+		return err
+	}
+	fmt.Printf("wukuard %s\n", version)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/loheagn/wukuard/grpc"
+)
+
+func TestIsLegacyServerConfig(t *testing.T) {
+	commands := map[string]string{"run": "", "migrate": "", "token": ""}
+	confPath := filepath.Join(t.TempDir(), "wukuard.conf")
+	if err := os.WriteFile(confPath, []byte("port: 9618\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{confPath}, true},
+		{[]string{confPath, "-extra"}, true},
+		{[]string{"/etc/wukuard.conf"}, true},
+		{[]string{"config.yaml"}, true},
+		{[]string{"migrate"}, false},
+		{[]string{"Run"}, false},
+		{[]string{"token", "-hostname", "a"}, false},
+		{[]string{"-config", confPath}, false},
+		{[]string{"unknown", "-config", confPath}, false},
+	} {
+		if got := isLegacyServerConfig(test.args, commands); got != test.want {
+			t.Errorf("isLegacyServerConfig(%q) = %v, want %v", test.args, got, test.want)
+		}
+	}
+}
+
+func TestMergePeerAddress(t *testing.T) {
+	for _, test := range []struct {
+		args       []string
+		allowedIPs string
+		want       string
+	}{
+		{[]string{"-address", "10.0.0.2/24"}, "10.0.0.1/32", ""},
+		{[]string{"-address", "10.0.0.2/24"}, "10.0.0.1/32, 172.16.0.0/16", "10.0.0.1/32, 172.16.0.0/16"},
+		{[]string{"-address", "10.0.0.2/24", "-allowed-ips", "10.0.0.2/32"}, "10.0.0.1/32", "10.0.0.2/32"},
+		{[]string{"-address", "10.0.0.1/24"}, "10.0.0.1/32", "10.0.0.1/32"},
+		{[]string{"-network", "lab"}, "10.0.0.1/32, 172.16.0.0/16", ""},
+	} {
+		peer := &pb.Peer{}
+		fs := newFlagSet("peer update", "", "")
+		peerFlags(fs, peer)
+		if err := fs.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		current := &pb.Peer{Network: "default", Address: "10.0.0.1/24", AllowedIPs: test.allowedIPs}
+		mergePeer(fs, current, peer)
+		if current.AllowedIPs != test.want {
+			t.Errorf("%q over %s: allowedIPs = %q, want %q", test.args, test.allowedIPs, current.AllowedIPs, test.want)
+		}
+	}
+}
+
+func TestClientNICEnv(t *testing.T) {
+	t.Setenv("WUKUARD_INTERFACE", "eth1")
+	conf, err := parseClientCommand([]string{"-server", "192.0.2.1:9618"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.NIC != "eth1" {
+		t.Errorf("nic from the deprecated variable = %q", conf.NIC)
+	}
+	t.Setenv("WUKUARD_NIC", "eth2")
+	if conf, err = parseClientCommand([]string{"-server", "192.0.2.1:9618"}); err != nil || conf.NIC != "eth2" {
+		t.Errorf("nic = %q, %v", conf.NIC, err)
+	}
+}
+
+func TestAdminClientCleartext(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:9618": true,
+		"[::1]:9618":     true,
+		"localhost:9618": true,
+		"192.0.2.1:9618": false,
+		"wukuard.test":   false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%s) = %v", addr, got)
+		}
+	}
+	client := &adminClient{server: "192.0.2.1:9618", token: "secret"}
+	err := client.call(func(context.Context, pb.AdminClient) error {
+		t.Error("token sent in cleartext")
+		return nil
+	})
+	if !errors.Is(err, errUsage) {
+		t.Errorf("call without TLS = %v", err)
+	}
+}
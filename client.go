@@ -3,13 +3,15 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"os"
-	"os/exec"
-	"regexp"
+	"os/signal"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -17,6 +19,8 @@ import (
 
 	pb "github.com/loheagn/wukuard/grpc"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
 )
 
 type InterfaceConf struct {
@@ -25,6 +29,7 @@ type InterfaceConf struct {
 	ListenPort int32
 	PostUp     string
 	PreDown    string
+	DNS        string
 }
 
 type PeerConf struct {
@@ -37,15 +42,181 @@ type PeerConf struct {
 type WgConf struct {
 	interfaceConf *InterfaceConf
 	peerConfList  []*PeerConf
+	// forward is true if the host forwards the traffic of other peers, as a relay or a gateway
+	forward bool
+}
+
+// ClientConfig is what the client can be configured with, from its config file and flags
+type ClientConfig struct {
+	// Server is the address of the server, host:port
+	Server string `yaml:"server"`
+	// NIC is the network interface whose MAC address is reported to the server
+	NIC string `yaml:"nic"`
+	// Network is the network of the server joined through Interface, the default one if empty
+	Network string `yaml:"network"`
+	// Interface is the name of the WireGuard interface
+	Interface string `yaml:"interface"`
+	// ConfigDir keeps the private key, the credential and the configuration of wg-quick
+	ConfigDir string `yaml:"configDir"`
+	// KeyFile and CredentialFile default to <interface>.key and <interface>.credential in ConfigDir
+	KeyFile        string `yaml:"keyFile"`
+	CredentialFile string `yaml:"credentialFile"`
+	// ServiceName is the systemd unit of the wg-quick backend, wg-quick@<interface>.service by default
+	ServiceName string `yaml:"serviceName"`
+	// ListenPort is the port advertised in the endpoint of the client until the server configures the interface
+	ListenPort int `yaml:"listenPort"`
+	// StunServer is the host:port of a STUN server asked for the public address of the client, if set
+	StunServer string `yaml:"stunServer"`
+	// Interval is how often the server is polled while the network stream is broken
+	Interval time.Duration `yaml:"interval"`
+	// Backend is netlink or wg-quick
+	Backend string `yaml:"backend"`
+	// JoinToken is only needed until the client is registered
+	JoinToken string          `yaml:"joinToken"`
+	TLS       ClientTLSConfig `yaml:"tls"`
+	// Networks joins several networks at once, each one through its own interface
+	Networks []ClientNetworkConfig `yaml:"networks"`
+	// MetricsAddr is the host:port to expose the metrics of the client on, if set
+	MetricsAddr string    `yaml:"metricsAddr"`
+	Log         LogConfig `yaml:"log"`
+	// KeepInterface leaves the WireGuard interfaces up when the client stops, they're removed by default
+	KeepInterface bool `yaml:"keepInterface"`
+}
+
+// ClientNetworkConfig overrides the fields of ClientConfig for one of its networks
+type ClientNetworkConfig struct {
+	Name string `yaml:"name"`
+	// Interface is the name of the network by default
+	Interface  string `yaml:"interface"`
+	ListenPort int    `yaml:"listenPort"`
+	JoinToken  string `yaml:"joinToken"`
+}
+
+func defaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		Interface:  "wukuard",
+		ConfigDir:  "/etc/wireguard",
+		ListenPort: defaultListenPort,
+		Interval:   10 * time.Second,
+		Backend:    backendNetlink,
+	}
+}
+
+func (conf *ClientConfig) confFilename() string {
+	return filepath.Join(conf.ConfigDir, conf.Interface+".conf")
+}
+
+func (conf *ClientConfig) privateKeyFilename() string {
+	if conf.KeyFile != "" {
+		return conf.KeyFile
+	}
+	return filepath.Join(conf.ConfigDir, conf.Interface+".key")
+}
+
+func (conf *ClientConfig) credentialFilename() string {
+	if conf.CredentialFile != "" {
+		return conf.CredentialFile
+	}
+	return filepath.Join(conf.ConfigDir, conf.Interface+".credential")
+}
+
+// networkFilename keeps the last network applied to the interface, restored when the server can't be reached
+func (conf *ClientConfig) networkFilename() string {
+	return filepath.Join(conf.ConfigDir, conf.Interface+".network")
+}
+
+func (conf *ClientConfig) serviceName() string {
+	if conf.ServiceName != "" {
+		return conf.ServiceName
+	}
+	return fmt.Sprintf("wg-quick@%s.service", conf.Interface)
+}
+
+// networkConfigs returns the config of every network to join
+func (conf *ClientConfig) networkConfigs() ([]*ClientConfig, error) {
+	if len(conf.Networks) <= 0 {
+		return []*ClientConfig{conf}, nil
+	}
+	var confs []*ClientConfig
+	interfaces := make(map[string]bool)
+	for _, network := range conf.Networks {
+		if network.Name == "" {
+			return nil, errors.New("invalid config: a network has no name")
+		}
+		networkConf := *conf
+		networkConf.Networks = nil
+		networkConf.Network, networkConf.Interface, networkConf.JoinToken = network.Name, network.Interface, network.JoinToken
+		if networkConf.Interface == "" {
+			networkConf.Interface = network.Name
+		}
+		if network.ListenPort != 0 {
+			networkConf.ListenPort = network.ListenPort
+		}
+		// the files of every network are named after its interface
+		networkConf.KeyFile, networkConf.CredentialFile, networkConf.ServiceName = "", "", ""
+		if interfaces[networkConf.Interface] {
+			return nil, fmt.Errorf("invalid config: interface %s is used by two networks", networkConf.Interface)
+		}
+		interfaces[networkConf.Interface] = true
+		confs = append(confs, &networkConf)
+	}
+	return confs, nil
+}
+
+// loadClientConfig reads the config file in confPath into conf, the keys missing in the file keep their value
+func loadClientConfig(confPath string, conf *ClientConfig) error {
+	confFileBytes, err := os.ReadFile(confPath)
+	if err != nil {
+		return fmt.Errorf("invalid config path: %w", err)
+	}
+	if err = yaml.Unmarshal(confFileBytes, conf); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
 }
 
 var (
 	serverIP       string
 	serverGrpcPort string
-	interfaceName  string // the name of network interface
 )
 
-var wgMutex sync.Mutex
+// agent keeps the WireGuard interface of one network in sync with the server
+type agent struct {
+	conf       *ClientConfig
+	privateKey string // generated locally, never sent to the server
+	publicKey  string
+	credential string // returned by the server on registration
+	backend    WireGuardBackend
+	// log carries the interface and the network of the agent
+	log *fieldLogger
+	// listenPort is the port of the interface once configured
+	listenPort int32
+	// status is the state of the interface reported to the server
+	status string
+	// stunAddress is the public IP learnt from the STUN server at stunCheckedAt
+	stunAddress   string
+	stunCheckedAt time.Time
+	mu            sync.Mutex
+}
+
+func newAgent(conf *ClientConfig) (*agent, error) {
+	a := &agent{conf: conf, status: "starting", log: logger.with("interface", conf.Interface, "network", conf.Network)}
+	var err error
+	a.privateKey, err = loadOrCreatePrivateKey(conf.privateKeyFilename())
+	if err != nil {
+		return nil, fmt.Errorf("load private key: %w", err)
+	}
+	a.publicKey, err = publicKeyOf(a.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("derive public key: %w", err)
+	}
+	a.log.info("use public key", "public_key", a.publicKey)
+	a.backend, err = newWireGuardBackend(conf)
+	if err != nil {
+		return nil, fmt.Errorf("init WireGuard backend: %w", err)
+	}
+	return a, nil
+}
 
 func (interfaceConf InterfaceConf) generateString() string {
 	return fmt.Sprintf(`
@@ -55,8 +226,16 @@ Address = %s
 ListenPort = %d
 PostUp = %s
 PreDown = %s
+%s
+`, interfaceConf.PrivateKey, interfaceConf.Address, interfaceConf.ListenPort, interfaceConf.PostUp, interfaceConf.PreDown, interfaceConf.dnsLine())
+}
 
-`, interfaceConf.PrivateKey, interfaceConf.Address, interfaceConf.ListenPort, interfaceConf.PostUp, interfaceConf.PreDown)
+// dnsLine is only written with DNS servers, as wg-quick calls resolvconf for any DNS line
+func (interfaceConf InterfaceConf) dnsLine() string {
+	if interfaceConf.DNS == "" {
+		return ""
+	}
+	return fmt.Sprintf("DNS = %s\n", interfaceConf.DNS)
 }
 
 func (peerConf PeerConf) generateString() string {
@@ -79,64 +258,21 @@ func (wgConf WgConf) generateString() string {
 	return buf.String()
 }
 
-const (
-	basePath     = "/etc/wireguard/"
-	confFilename = "/etc/wireguard/wukuard.conf"
-	serviceName  = "wg-quick@wukuard.service"
-)
-
-func getCurrentConf() (string, error) {
-	wholeConfStr, err := readFile(confFilename)
+// getLocalIP returns the local IP the host reaches the server from
+func getLocalIP() (string, error) {
+	// nothing is sent, dialing UDP only picks the route
+	conn, err := net.Dial("udp", net.JoinHostPort(serverIP, "80"))
 	if err != nil {
 		return "", err
 	}
-	return wholeConfStr, nil
-}
-
-func checkServiceIsRunning() bool {
-	output, err := exec.Command("systemctl", "status", serviceName).Output()
-	if err != nil {
-		return false
-	}
-	matched, err := regexp.MatchString("Active: active", string(output))
-	return err == nil && matched
-}
-
-func prepareConfFile() error {
-	syscall.Umask(0022)
-	if _, err := os.Stat(basePath); os.IsNotExist(err) {
-		err = os.MkdirAll(basePath, os.ModePerm)
-		if err != nil {
-			return err
-		}
-	}
-	// generate the wg conf
-	if _, err := os.Stat(confFilename); os.IsNotExist(err) {
-		confFile, err := os.Create(confFilename)
-		if err != nil {
-			return err
-		}
-		_ = confFile.Close()
-	} else {
-		return err
-	}
-	return nil
-}
-
-func getLocalIP() string {
-	conn, err := net.Dial("udp", serverIP+":80")
-
-	if err != nil {
-		log.Fatal(err)
-	}
 	defer conn.Close()
 
 	localAddr := conn.LocalAddr().(*net.UDPAddr)
-	return localAddr.IP.String()
+	return localAddr.IP.String(), nil
 }
 
-func getMacAddress() string {
-	if interfaceName == "" {
+func getMacAddress(nic string) string {
+	if nic == "" {
 		return ""
 	}
 	ifas, err := net.Interfaces()
@@ -144,7 +280,7 @@ func getMacAddress() string {
 		return ""
 	}
 	for _, v := range ifas {
-		if v.Name == interfaceName {
+		if v.Name == nic {
 			return v.HardwareAddr.String()
 		}
 	}
@@ -159,33 +295,42 @@ func getHostname() string {
 	return hostname
 }
 
-func parseServerAddr(addr string) {
+func parseServerAddr(addr string) error {
 	ip, port, err := net.SplitHostPort(addr)
 	if err != nil {
-		log.Panic(err)
+		return fmt.Errorf("invalid server address: %w", err)
 	}
 	serverIP, serverGrpcPort = ip, port
+	return nil
 }
 
-func mapGrpcResponse(network *pb.NetWorkResponse) *WgConf {
+func (a *agent) mapGrpcResponse(network *pb.NetWorkResponse) *WgConf {
 	wgConf := &WgConf{}
 	interfaceResponse := network.GetInterfaceResponse()
-	if interfaceResponse == nil {
+	if interfaceResponse == nil || interfaceResponse.Address == "" {
+		// wg-quick would bring the interface up without address, cutting the client off the mesh
 		return nil
 	}
 	wgConf.interfaceConf = &InterfaceConf{
-		PrivateKey: interfaceResponse.PrivateKey,
+		PrivateKey: a.privateKey,
 		Address:    interfaceResponse.Address,
 		ListenPort: interfaceResponse.ListenPort,
 		PostUp:     interfaceResponse.PostUp,
 		PreDown:    interfaceResponse.PreDown,
+		DNS:        interfaceResponse.Dns,
 	}
+	if interfaceResponse.Routes != "" {
+		postUp, preDown := gatewayHooks(interfaceResponse.Address)
+		wgConf.interfaceConf.PostUp = joinHooks(postUp, interfaceResponse.PostUp)
+		wgConf.interfaceConf.PreDown = joinHooks(interfaceResponse.PreDown, preDown)
+	}
+	wgConf.forward = interfaceResponse.Relay || interfaceResponse.Routes != ""
 
 	var peerConfList []*PeerConf
-	localIP := getLocalIP()
 	for _, peer := range network.PeerList {
-		if strings.HasPrefix(peer.Endpoint, localIP) {
-			// this peer describes itself
+		if (peer.Id != 0 && peer.Id == interfaceResponse.Id) || peer.PublicKey == a.publicKey {
+			// the server never sends the client itself, but WireGuard would reject its own key
+			a.log.warn("skip the peer describing the client itself", "peer", peer.Id)
 			continue
 		}
 		peerConfList = append(peerConfList, &PeerConf{
@@ -195,86 +340,463 @@ func mapGrpcResponse(network *pb.NetWorkResponse) *WgConf {
 			PersistentKeepalive: peer.PersistentKeepalive,
 		})
 	}
-	// sort peerConfList by endpoint
+	// sort peerConfList by public key, which unlike endpoints is always set
 	sort.Slice(peerConfList, func(i, j int) bool {
-		return peerConfList[i].Endpoint < peerConfList[j].Endpoint
+		return peerConfList[i].PublicKey < peerConfList[j].PublicKey
 	})
 	wgConf.peerConfList = peerConfList
 
 	return wgConf
 }
 
-func syncWgConf(inputConf *WgConf) {
-	var err error
+func (a *agent) syncWgConf(inputConf *WgConf) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	wgMutex.Lock()
-	defer wgMutex.Unlock()
-
-	if inputConf == nil || inputConf.interfaceConf == nil {
-		err = writeFile(confFilename, "")
-		checkErr(err)
-		if checkServiceIsRunning() {
-			log.Println("INFO: stop wukuard service")
-			_ = exec.Command("/bin/bash", "-c", "ip link delete wukuard").Run()
-			err = exec.Command("systemctl", "stop", serviceName).Run()
-			checkErr(err)
+	if inputConf.forward {
+		if err := enableForwarding(); err != nil {
+			a.log.error("enable forwarding", "err", err)
 		}
+	}
+	applied, err := reconcile(a.backend, inputConf, a.log)
+	if err != nil {
+		a.log.error("configure the interface", "err", err)
+		clientAppliesTotal.WithLabelValues(a.conf.Interface, "error").Inc()
+		a.status = err.Error()
+		return err
+	}
+	if applied {
+		clientAppliesTotal.WithLabelValues(a.conf.Interface, "ok").Inc()
+	} else {
+		clientAppliesTotal.WithLabelValues(a.conf.Interface, "unchanged").Inc()
+	}
+	clientLastSync.WithLabelValues(a.conf.Interface).SetToCurrentTime()
+	a.listenPort = inputConf.interfaceConf.ListenPort
+	a.status = "ok"
+	return nil
+}
+
+// errDeletedByServer tells that the server deleted the peer of the client
+var errDeletedByServer = errors.New("the peer was deleted from the server")
+
+// applyNetwork configures the interface with the network received from the server,
+// and saves it as the last known good one once applied.
+// A network without interface is ignored, so that the interface is only removed when the peer is deleted.
+func (a *agent) applyNetwork(network *pb.NetWorkResponse) error {
+	if network.Deleted {
+		return errDeletedByServer
+	}
+	wgConf := a.mapGrpcResponse(network)
+	if wgConf == nil {
+		a.log.warn("the network of the server has no interface address, keep the current one")
+		return nil
+	}
+	if a.syncWgConf(wgConf) != nil {
+		return nil
+	}
+	content, err := protojson.Marshal(network)
+	if err == nil {
+		err = os.WriteFile(a.conf.networkFilename(), content, 0600)
+	}
+	if err != nil {
+		a.log.warn("save the network", "err", err)
+	}
+	return nil
+}
+
+// restoreNetwork applies the last network saved, so that the mesh comes up even if the server can't be reached
+func (a *agent) restoreNetwork() {
+	content, err := os.ReadFile(a.conf.networkFilename())
+	if errors.Is(err, os.ErrNotExist) {
 		return
 	}
+	network := &pb.NetWorkResponse{}
+	if err == nil {
+		err = protojson.Unmarshal(content, network)
+	}
+	if err != nil {
+		a.log.warn("read the saved network", "err", err)
+		return
+	}
+	if wgConf := a.mapGrpcResponse(network); wgConf != nil {
+		a.log.info("restore the saved network", "version", network.Version)
+		_ = a.syncWgConf(wgConf)
+	}
+}
 
-	err = prepareConfFile()
-	checkErr(err)
+// leave removes the interface, and forgets the network and the credential of the deleted peer,
+// so that the client can join again with a new join token
+func (a *agent) leave() {
+	a.log.warn("the peer was deleted from the server, remove the interface")
+	a.mu.Lock()
+	if err := a.backend.Down(); err != nil {
+		a.log.error("remove the interface", "err", err)
+	}
+	a.mu.Unlock()
+	for _, filename := range []string{a.conf.networkFilename(), a.conf.credentialFilename()} {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			a.log.error("remove file", "file", filename, "err", err)
+		}
+	}
+}
 
-	wholeConfStr, err := getCurrentConf()
-	inputConfStr := inputConf.generateString()
-	if wholeConfStr != inputConfStr || !checkServiceIsRunning() {
-		err = writeFile(confFilename, inputConfStr)
-		checkErr(err)
-		log.Println("INFO: restart wukuard service")
-		err = exec.Command("systemctl", "restart", serviceName).Run()
-		if err != nil {
-			// stupid but effective
-			_ = exec.Command("/bin/bash", "-c", "ip link delete wukuard").Run()
-			err = exec.Command("systemctl", "restart", serviceName).Run()
-			checkErr(err)
+// publicAddress returns the public IP of the client as seen by the STUN server, if any,
+// asking it again once stunInterval has passed
+func (a *agent) publicAddress() string {
+	if a.conf.StunServer == "" {
+		return ""
+	}
+	if time.Since(a.stunCheckedAt) < stunInterval {
+		return a.stunAddress
+	}
+	a.stunCheckedAt = time.Now()
+	mapped, local, err := stunDiscover(a.conf.StunServer)
+	if err != nil {
+		a.log.warn("ask the STUN server for the public address", "stun_server", a.conf.StunServer, "err", err)
+		return a.stunAddress
+	}
+	if ip := mapped.IP.String(); ip != a.stunAddress {
+		a.log.info("learnt the public address", "address", ip)
+		if mapped.Port != local.Port {
+			// the public endpoint is the public address with the listen port
+			a.log.warn("the NAT doesn't keep the ports, the peers may not reach the public endpoint, consider a relay",
+				"local_port", local.Port, "mapped_port", mapped.Port)
 		}
-		return
+		a.stunAddress = ip
 	}
+	return a.stunAddress
 }
 
-func buildPeerRequest() *pb.PeerRequest {
+func (a *agent) buildPeerRequest() *pb.PeerRequest {
+	a.mu.Lock()
+	listenPort, status := a.listenPort, a.status
+	stats := a.statsToReport()
+	a.mu.Unlock()
+	if listenPort == 0 {
+		listenPort = int32(a.conf.ListenPort)
+	}
+	var endpoint string
+	if localIP, err := getLocalIP(); err == nil {
+		endpoint = net.JoinHostPort(localIP, strconv.Itoa(int(listenPort)))
+	} else {
+		// the server still sees the public address of the client
+		a.log.warn("find the local address", "err", err)
+	}
 	return &pb.PeerRequest{
-		Endpoint:   fmt.Sprintf("%s:9619", getLocalIP()),
-		MacAddress: getMacAddress(),
+		Endpoint:    endpoint,
+		MacAddress:  getMacAddress(a.conf.NIC),
+		Hostname:    getHostname(),
+		PublicKey:   a.publicKey,
+		Credential:  a.credential,
+		Network:     a.conf.Network,
+		StunAddress: a.publicAddress(),
+		Version:     version,
+		Status:      status,
+		Stats:       stats,
+	}
+}
+
+// loadOrRegisterCredential reads the credential saved by a previous registration,
+// or registers to the server with the join token and saves the returned credential.
+func (a *agent) loadOrRegisterCredential(ctx context.Context, c pb.SyncNetClient) (string, error) {
+	credentialFilename := a.conf.credentialFilename()
+	content, err := readFile(credentialFilename)
+	if err == nil && strings.TrimSpace(content) != "" {
+		return strings.TrimSpace(content), nil
+	}
+	if a.conf.JoinToken == "" {
+		return "", fmt.Errorf("no credential found in %s and no join token provided", credentialFilename)
+	}
+	resp, err := c.Register(ctx, &pb.RegisterRequest{
+		JoinToken:  a.conf.JoinToken,
+		MacAddress: getMacAddress(a.conf.NIC),
 		Hostname:   getHostname(),
+		PublicKey:  a.publicKey,
+		Network:    a.conf.Network,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(credentialFilename, []byte(resp.Credential+"\n"), 0600); err != nil {
+		return "", err
 	}
+	a.log.info("registered to the server")
+	return resp.Credential, nil
 }
 
-func clientMain(serverAddr, inputInterfaceName string) {
-	parseServerAddr(serverAddr)
-	log.Printf("INFO: try to connect to the server(%s:%s)......\n", serverIP, serverGrpcPort)
-	interfaceName = inputInterfaceName
-	conn, err := grpc.Dial(fmt.Sprintf("%s:%s", serverIP, serverGrpcPort), grpc.WithInsecure(), grpc.WithBlock())
+// run registers if needed and follows the network until ctx is done
+func (a *agent) run(ctx context.Context, c pb.SyncNetClient) error {
+	var err error
+	a.credential, err = a.loadOrRegisterCredential(ctx, c)
+	if ctx.Err() != nil {
+		return nil
+	}
 	if err != nil {
-		log.Fatalf("ERROR: did not connect: %v", err)
+		return fmt.Errorf("%s: register: %w", a.conf.Interface, err)
 	}
-	log.Printf("INFO: connected to the server(%s:%s)......\n", serverIP, serverGrpcPort)
-	defer conn.Close()
-	c := pb.NewSyncNetClient(conn)
 
-	t := time.NewTicker(10 * time.Second)
+	t := time.NewTicker(a.conf.Interval)
 	defer t.Stop()
+	for {
+		// follow the network pushed by the server,
+		// and fall back to polling it until the stream can be opened again
+		err = a.watchNetwork(ctx, c)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != errDeletedByServer {
+			a.log.warn("network stream broken, fall back to polling", "err", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-t.C:
+			}
+			err = a.poll(ctx, c)
+		}
+		if err == errDeletedByServer {
+			a.leave()
+			return fmt.Errorf("%s: %w", a.conf.Interface, err)
+		}
+	}
+}
+
+// poll asks the server for the network once, the interface keeps the current one if the server can't answer
+func (a *agent) poll(ctx context.Context, c pb.SyncNetClient) error {
+	resp, err := c.HeartBeat(ctx, a.buildPeerRequest())
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		a.log.error("poll the server, keep the current network", "err", err)
+		return nil
+	}
+	return a.applyNetwork(resp)
+}
+
+// watchNetwork applies every network pushed by the server until the stream breaks
+func (a *agent) watchNetwork(ctx context.Context, c pb.SyncNetClient) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.WatchNetwork(ctx, a.buildPeerRequest())
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	defer func() {
-		_ = exec.Command("/bin/bash", "-c", "ip link delete wukuard").Run()
-		err = exec.Command("systemctl", "stop", serviceName).Run()
-		checkErr(err)
+		cancel()
+		wg.Wait()
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.report(ctx, c)
 	}()
 	for {
-		<-t.C
-		resp, err := c.HeartBeat(context.Background(), buildPeerRequest())
+		resp, err := stream.Recv()
 		if err != nil {
-			log.Printf("ERROR: get error from grpc server: %s\n", err.Error())
+			return err
+		}
+		a.log.info("received network", "version", resp.Version)
+		if err = a.applyNetwork(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// report sends heartbeats until ctx is done, so that the server learns the status of the client
+// while the network comes from the stream
+func (a *agent) report(ctx context.Context, c pb.SyncNetClient) {
+	t := time.NewTicker(reportInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if _, err := c.HeartBeat(ctx, a.buildPeerRequest()); err != nil && ctx.Err() == nil {
+			a.log.warn("report the status", "err", err)
+		}
+	}
+}
+
+// clientMain joins every network of conf, each one through its own interface, until SIGINT or SIGTERM.
+// SIGHUP restarts the agents with the config returned by load.
+func clientMain(conf *ClientConfig, load func() (*ClientConfig, error)) error {
+	if err := configureLogging(conf.Log); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	c := &client{}
+	clientMetrics.MustRegister(c)
+	if conf.MetricsAddr != "" {
+		metricsServer, err := serveMetrics(conf.MetricsAddr, clientMetrics, nil)
+		if err != nil {
+			return fmt.Errorf("serve metrics: %w", err)
+		}
+		defer shutdownHTTP(metricsServer)
+	}
+	signals := notifySignals()
+	defer signal.Stop(signals)
+	if err := c.start(conf); err != nil {
+		return err
+	}
+	for {
+		select {
+		case err := <-c.done:
+			return err
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				if err := c.reload(load); err != nil {
+					return err
+				}
+				continue
+			}
+			logger.info("stop the client", "signal", sig)
+			c.stop()
+			c.release(func(a *agent) bool {
+				return !a.conf.KeepInterface
+			})
+			return nil
+		}
+	}
+}
+
+// client runs the agents of one config
+type client struct {
+	mu     sync.Mutex
+	agents []*agent
+	cancel context.CancelFunc
+	// done gets the first error of the agents once they all stopped
+	done chan error
+}
+
+// start restores the interfaces of conf, then connects to the server and runs their agents in the background
+func (c *client) start(conf *ClientConfig) error {
+	if err := parseServerAddr(conf.Server); err != nil {
+		return err
+	}
+	confs, err := conf.networkConfigs()
+	if err != nil {
+		return err
+	}
+	transportOption, err := conf.TLS.dialOption()
+	if err != nil {
+		return fmt.Errorf("load TLS config: %w", err)
+	}
+	var agents []*agent
+	for _, networkConf := range confs {
+		a, err := newAgent(networkConf)
+		if err != nil {
+			return fmt.Errorf("%s: %w", networkConf.Interface, err)
+		}
+		agents = append(agents, a)
+	}
+	// the mesh doesn't wait for the server
+	for _, a := range agents {
+		a.restoreNetwork()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	c.mu.Lock()
+	c.agents, c.cancel, c.done = agents, cancel, done
+	c.mu.Unlock()
+
+	go func() {
+		done <- runAgents(ctx, agents, fmt.Sprintf("%s:%s", serverIP, serverGrpcPort), transportOption)
+	}()
+	return nil
+}
+
+// runAgents connects to the server and runs every agent until ctx is done
+func runAgents(ctx context.Context, agents []*agent, addr string, transportOption grpc.DialOption) error {
+	logger.info("connect to the server", "server", addr)
+	conn, err := grpc.DialContext(ctx, addr, transportOption, grpc.WithBlock())
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("did not connect: %w", err)
+	}
+	logger.info("connected to the server", "server", addr)
+	defer conn.Close()
+	sc := pb.NewSyncNetClient(conn)
+
+	// an agent which can't register doesn't stop the others
+	errs := make(chan error, len(agents))
+	for _, a := range agents {
+		go func(a *agent) {
+			errs <- a.run(ctx, sc)
+		}(a)
+	}
+	var firstErr error
+	for range agents {
+		if err = <-errs; err != nil {
+			logger.error("stop following the network", "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// stop waits for the agents to stop, leaving their interfaces as they are
+func (c *client) stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+	cancel()
+	<-done
+}
+
+// reload restarts the agents with the config returned by load, removing the interfaces it doesn't have anymore.
+// The current agents keep running if the config is invalid, the error tells that none could be restarted.
+func (c *client) reload(load func() (*ClientConfig, error)) error {
+	conf, err := load()
+	var confs []*ClientConfig
+	if err == nil {
+		err = configureLogging(conf.Log)
+	}
+	if err == nil {
+		confs, err = conf.networkConfigs()
+	}
+	if err != nil {
+		logger.error("reload the config, keep the current one", "err", err)
+		return nil
+	}
+	interfaces := make(map[string]bool)
+	for _, networkConf := range confs {
+		interfaces[networkConf.Interface] = true
+	}
+	c.stop()
+	c.release(func(a *agent) bool {
+		return !interfaces[a.conf.Interface]
+	})
+	if err = c.start(conf); err != nil {
+		return fmt.Errorf("restart with the reloaded config: %w", err)
+	}
+	logger.info("reloaded the config")
+	return nil
+}
+
+// release takes down the interfaces of the stopped agents matching remove, and closes their backends
+func (c *client) release(remove func(a *agent) bool) {
+	c.mu.Lock()
+	agents := c.agents
+	c.agents = nil
+	c.mu.Unlock()
+	for _, a := range agents {
+		a.mu.Lock()
+		if remove(a) {
+			a.log.info("remove the interface")
+			if err := a.backend.Down(); err != nil {
+				a.log.error("remove the interface", "err", err)
+			}
+			clientLastSync.DeleteLabelValues(a.conf.Interface)
+		}
+		if closer, ok := a.backend.(io.Closer); ok {
+			_ = closer.Close()
 		}
-		syncWgConf(mapGrpcResponse(resp))
+		a.mu.Unlock()
 	}
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	pb "github.com/loheagn/wukuard/grpc"
+)
+
+// panickingBackend fails the test if the interface is touched
+type panickingBackend struct {
+	WireGuardBackend
+}
+
+func TestApplyNetworkWithoutAddress(t *testing.T) {
+	a := &agent{conf: &ClientConfig{Interface: "wk0"}, backend: panickingBackend{}, log: logger}
+	for _, network := range []*pb.NetWorkResponse{
+		{},
+		{InterfaceResponse: &pb.InterfaceResponse{ListenPort: 9619}, PeerList: []*pb.PeerResponse{{PublicKey: "peer-a"}}},
+	} {
+		if err := a.applyNetwork(network); err != nil {
+			t.Errorf("apply %+v: %v", network, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultJoinTokenTTL = 24 * time.Hour
+	// defaultListenPort is the port of the peers created in a network without one, which is the one advertised by the client
+	defaultListenPort = 9619
+)
+
+// newSecret returns a random url-safe string used for join tokens and credentials
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashSecret is what we store instead of the secret itself
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// createJoinToken mints a one-time join token for the peer named hostname in network
+func (s *server) createJoinToken(network, hostname string, ttl time.Duration) (string, error) {
+	if _, err := s.store.GetNetwork(network); err != nil {
+		if errors.Is(err, errNotFound) {
+			return "", fmt.Errorf("unknown network %s", network)
+		}
+		return "", err
+	}
+	token, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = s.store.CreateJoinToken(&JoinToken{
+		TokenHash: hashSecret(token),
+		Network:   network,
+		Hostname:  hostname,
+		ExpiresAt: now.Add(ttl).Unix(),
+		CreatedAt: now.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeJoinToken marks the token as used and returns it.
+// A token can only be consumed once.
+func (s *server) consumeJoinToken(token string) (*JoinToken, error) {
+	now := time.Now().Unix()
+	joinToken, err := s.store.ConsumeJoinToken(hashSecret(token), now)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, errors.New("unknown or used join token")
+		}
+		return nil, err
+	}
+	if joinToken.ExpiresAt < now {
+		return nil, fmt.Errorf("join token for %s is expired", joinToken.Hostname)
+	}
+	return joinToken, nil
+}
+
+func (s *server) updatePeerCredential(ctx context.Context, record *PeerRecord, credential, publicKey string) *PeerRecord {
+	credentialHash := hashSecret(credential)
+	if err := s.store.UpdatePeerToken(record.ID, credentialHash, publicKey); err != nil {
+		loggerFrom(ctx).withPeer(record).error("save the credential", "err", err)
+		return nil
+	}
+	record.Token = credentialHash
+	record.PublicKey = publicKey
+	record.PrivateKey = ""
+	return record
+}
+
+func (s *server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if err := validateKey(req.PublicKey); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid public key: %s", err.Error())
+	}
+	log := loggerFrom(ctx).with("client_hostname", req.Hostname, "mac_address", req.MacAddress)
+	if identity, ok := certIdentity(ctx); ok && identity != req.Hostname {
+		// check before consuming the token so that a mismatched client can't burn it
+		log.warn("reject registration: client certificate of another host", "identity", identity)
+		return nil, status.Error(codes.PermissionDenied, "client certificate does not match hostname")
+	}
+	joinToken, err := s.consumeJoinToken(req.JoinToken)
+	if err != nil {
+		log.warn("reject registration: invalid join token", "err", err)
+		return nil, status.Error(codes.PermissionDenied, "invalid join token")
+	}
+	network, hostname := joinToken.Network, joinToken.Hostname
+	if req.Network != "" && req.Network != network {
+		log.warn("reject registration: join token of another network", "network", req.Network, "token_network", network)
+		return nil, status.Error(codes.PermissionDenied, "join token of another network")
+	}
+	if err = checkCertIdentity(ctx, hostname); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	credential, err := newSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	record := s.fetchRecordByHostname(ctx, network, hostname)
+	if record == nil {
+		// a new peer, allocate its address from the network
+		record = &PeerRecord{
+			Network:    network,
+			MacAddress: req.MacAddress,
+			Hostname:   hostname,
+			Token:      hashSecret(credential),
+			PublicKey:  req.PublicKey,
+			CreatedAt:  time.Now().Unix(),
+		}
+		record.UpdatedAt = record.CreatedAt
+		if err = s.ipam.createPeer(s.store, record); err != nil {
+			log.error("create peer", "hostname", hostname, "network", network, "err", err)
+			return nil, ipamStatus(err)
+		}
+		log.withPeer(record).info("created peer", "address", record.Address)
+	} else if s.updatePeerCredential(ctx, record, credential, req.PublicKey) == nil {
+		return nil, status.Error(codes.Internal, "failed to save credential")
+	}
+	log.withPeer(record).info("registered peer")
+	return &pb.RegisterResponse{Credential: credential}, nil
+}
+
+// tokenMain mints a join token for hostname in network and prints it
+func tokenMain(confPath, network, hostname string, ttl time.Duration) error {
+	conf, s, err := openServer(confPath)
+	if err != nil {
+		return err
+	}
+	defer s.store.Close()
+	if network == defaultNetworkName {
+		if err = ensureDefaultNetwork(s.store, conf.Network); err != nil {
+			return fmt.Errorf("invalid network: %w", err)
+		}
+	}
+
+	token, err := s.createJoinToken(network, hostname, ttl)
+	if err != nil {
+		return fmt.Errorf("create join token: %w", err)
+	}
+	fmt.Println(token)
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// healthInterval is how often the store is checked for the gRPC health service and the watchdog of systemd
+	healthInterval = 10 * time.Second
+	// healthTimeout bounds a check of the store
+	healthTimeout = 5 * time.Second
+)
+
+// pinger is implemented by the stores depending on a connection, such as a DB
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// checkStore tells whether the store is reachable, the stores without connection always are.
+// store must be the one opened, not a wrapper of the server that hides its Ping
+func checkStore(ctx context.Context, store PeerStore) error {
+	p, ok := store.(pinger)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+	return p.Ping(ctx)
+}
+
+// watchHealth sets the status of the gRPC health service from the reachability of the store until ctx is done,
+// and pets the watchdog of systemd while the store is reachable
+func watchHealth(ctx context.Context, store PeerStore, healthServer *health.Server) {
+	interval := healthInterval
+	if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+		if watchdog := time.Duration(usec) * time.Microsecond / 2; watchdog < interval {
+			interval = watchdog
+		}
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	serving := true
+	for {
+		servingStatus := healthpb.HealthCheckResponse_SERVING
+		err := checkStore(ctx, store)
+		if err != nil {
+			servingStatus = healthpb.HealthCheckResponse_NOT_SERVING
+			if serving {
+				logger.warn("store is unreachable", "err", err)
+			}
+		} else {
+			if !serving {
+				logger.info("store is reachable again")
+			}
+			if notifyErr := sdNotify("WATCHDOG=1"); notifyErr != nil {
+				logger.warn("pet the watchdog", "err", notifyErr)
+			}
+		}
+		serving = err == nil
+		healthServer.SetServingStatus("", servingStatus)
+		healthServer.SetServingStatus(pb.SyncNet_ServiceDesc.ServiceName, servingStatus)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// healthHandler serves /healthz, ok as long as the server runs,
+// and /readyz, ok only while the store is reachable
+func healthHandler(store PeerStore) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := checkStore(r.Context(), store); err != nil {
+			http.Error(w, "store is unreachable: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// sdNotify sends state, such as READY=1, to systemd if the unit is of type notify
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("notify systemd: %w", err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("notify systemd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errNoNetwork = errors.New("no CIDR in the network to allocate addresses from")
+
+// ipamError is a rejected allocation or assignment, as opposed to an error of the store
+type ipamError struct {
+	code codes.Code
+	err  error
+}
+
+func (e *ipamError) Error() string {
+	return e.err.Error()
+}
+
+// ipamStatus converts the errors of ipam to gRPC status
+func ipamStatus(err error) error {
+	var e *ipamError
+	if errors.As(err, &e) {
+		return status.Error(e.code, e.Error())
+	}
+	return storeError(err)
+}
+
+// ipam allocates the addresses of new peers from the CIDRs of their network,
+// and rejects addresses or AllowedIPs conflicting with other peers of the same network.
+// All the creations and updates of peers and networks go through it so that they can't race each other.
+type ipam struct {
+	mu sync.Mutex
+}
+
+func newIPAM() *ipam {
+	return &ipam{}
+}
+
+// parseNetworks parses the CIDRs of network
+func parseNetworks(network *NetworkRecord) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, cidr := range []string{network.CIDR, network.CIDR6} {
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, ipNet)
+	}
+	return networks, nil
+}
+
+// splitList splits a comma separated list such as Address or AllowedIPs
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCIDRList parses Address or AllowedIPs, keeping the host part of the address
+func parseCIDRList(value string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range splitList(value) {
+		ip, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		network.IP = ip
+		nets = append(nets, network)
+	}
+	return nets, nil
+}
+
+func hostBits(ip net.IP) int {
+	if ip.To4() != nil {
+		return 32
+	}
+	return 128
+}
+
+// derivedAllowedIPs routes the addresses of a peer to it, one /32 or /128 per address
+func derivedAllowedIPs(address string) string {
+	nets, err := parseCIDRList(address)
+	if err != nil {
+		return ""
+	}
+	var allowedIPs []string
+	for _, network := range nets {
+		allowedIPs = append(allowedIPs, fmt.Sprintf("%s/%d", network.IP, hostBits(network.IP)))
+	}
+	return strings.Join(allowedIPs, ", ")
+}
+
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP.Mask(b.Mask)) || b.Contains(a.IP.Mask(a.Mask))
+}
+
+// nextIP returns ip + 1, or nil if it overflows
+func nextIP(ip net.IP) net.IP {
+	n := new(big.Int).Add(new(big.Int).SetBytes(ip), big.NewInt(1))
+	bytes := n.Bytes()
+	if len(bytes) > len(ip) {
+		return nil
+	}
+	next := make(net.IP, len(ip))
+	copy(next[len(ip)-len(bytes):], bytes)
+	return next
+}
+
+// allocateIn returns the first free host address in network
+func allocateIn(network *net.IPNet, used map[string]bool) (net.IP, error) {
+	ip := network.IP.Mask(network.Mask)
+	if ip.To4() != nil {
+		ip = ip.To4()
+	}
+	// skip the network address
+	for ip = nextIP(ip); ip != nil && network.Contains(ip); ip = nextIP(ip) {
+		if ip.To4() != nil && !network.Contains(nextIP(ip)) {
+			// the broadcast address
+			break
+		}
+		if !used[ip.String()] {
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("no free address in %s", network)
+}
+
+// allocate assigns a free address in every network to record, together with the derived AllowedIPs
+func allocate(networks []*net.IPNet, record *PeerRecord, others []*PeerRecord) error {
+	if len(networks) <= 0 {
+		return errNoNetwork
+	}
+	used := make(map[string]bool)
+	for _, other := range others {
+		nets, _ := parseCIDRList(other.Address)
+		for _, network := range nets {
+			used[network.IP.String()] = true
+		}
+	}
+	var addresses []string
+	for _, network := range networks {
+		ip, err := allocateIn(network, used)
+		if err != nil {
+			return err
+		}
+		ones, _ := network.Mask.Size()
+		addresses = append(addresses, fmt.Sprintf("%s/%d", ip, ones))
+	}
+	record.Address = strings.Join(addresses, ", ")
+	record.AllowedIPs = derivedAllowedIPs(record.Address)
+	return nil
+}
+
+// checkNetworks rejects the addresses outside of networks
+func checkNetworks(networks []*net.IPNet, record *PeerRecord) error {
+	addresses, err := parseCIDRList(record.Address)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if !inNetworks(networks, address.IP) {
+			return fmt.Errorf("address %s is out of the network", address.IP)
+		}
+	}
+	return nil
+}
+
+// checkConflicts rejects the addresses used by other peers and AllowedIPs overlapping those of other peers
+func checkConflicts(record *PeerRecord, others []*PeerRecord) error {
+	addresses, err := parseCIDRList(record.Address)
+	if err != nil {
+		return err
+	}
+	allowedIPs, err := parseCIDRList(record.AllowedIPs)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID == record.ID {
+			continue
+		}
+		otherAddresses, _ := parseCIDRList(other.Address)
+		for _, address := range addresses {
+			for _, otherAddress := range otherAddresses {
+				if address.IP.Equal(otherAddress.IP) {
+					return fmt.Errorf("address %s is used by %s", address.IP, other.Hostname)
+				}
+			}
+		}
+		otherAllowedIPs, _ := parseCIDRList(other.AllowedIPs)
+		for _, allowed := range allowedIPs {
+			for _, otherAllowed := range otherAllowedIPs {
+				if overlaps(allowed, otherAllowed) {
+					return fmt.Errorf("allowedIPs %s overlaps %s of %s", allowed, otherAllowed, other.Hostname)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isDefaultRoute reports whether ipNet is 0.0.0.0/0 or ::/0
+func isDefaultRoute(ipNet *net.IPNet) bool {
+	ones, _ := ipNet.Mask.Size()
+	return ones == 0
+}
+
+// checkRoutes rejects the routes overlapping the networks, or the AllowedIPs and routes of other peers,
+// and a second default route of the same family in the network.
+// It also rejects AllowedIPs overlapping the routes of other peers.
+func checkRoutes(networks []*net.IPNet, record *PeerRecord, others []*PeerRecord) error {
+	routes, err := parseCIDRList(record.Routes)
+	if err != nil {
+		return err
+	}
+	allowedIPs, _ := parseCIDRList(record.AllowedIPs)
+	for _, route := range routes {
+		if isDefaultRoute(route) {
+			continue
+		}
+		for _, network := range networks {
+			if overlaps(route, network) {
+				return fmt.Errorf("route %s overlaps the network %s", route, network)
+			}
+		}
+	}
+	for _, other := range others {
+		if other.ID == record.ID {
+			continue
+		}
+		otherRoutes, _ := parseCIDRList(other.Routes)
+		otherAllowedIPs, _ := parseCIDRList(other.AllowedIPs)
+		for _, route := range routes {
+			for _, otherRoute := range otherRoutes {
+				if isDefaultRoute(route) != isDefaultRoute(otherRoute) {
+					continue
+				}
+				if overlaps(route, otherRoute) && (hostBits(route.IP) == hostBits(otherRoute.IP)) {
+					return fmt.Errorf("route %s overlaps %s of %s", route, otherRoute, other.Hostname)
+				}
+			}
+			if isDefaultRoute(route) {
+				continue
+			}
+			for _, otherAllowed := range otherAllowedIPs {
+				if overlaps(route, otherAllowed) {
+					return fmt.Errorf("route %s overlaps the allowedIPs %s of %s", route, otherAllowed, other.Hostname)
+				}
+			}
+		}
+		for _, allowed := range allowedIPs {
+			for _, otherRoute := range otherRoutes {
+				if !isDefaultRoute(otherRoute) && overlaps(allowed, otherRoute) {
+					return fmt.Errorf("allowedIPs %s overlaps the route %s of %s", allowed, otherRoute, other.Hostname)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inNetworks reports whether ip belongs to the network of its family in networks, if any
+func inNetworks(networks []*net.IPNet, ip net.IP) bool {
+	configured := false
+	for _, network := range networks {
+		if (network.IP.To4() != nil) != (ip.To4() != nil) {
+			continue
+		}
+		configured = true
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !configured
+}
+
+// getNetwork returns the network named name, rejecting unknown networks
+func getNetwork(store PeerStore, name string) (*NetworkRecord, []*net.IPNet, error) {
+	network, err := store.GetNetwork(name)
+	if errors.Is(err, errNotFound) {
+		return nil, nil, &ipamError{code: codes.InvalidArgument, err: fmt.Errorf("unknown network %s", name)}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	networks, err := parseNetworks(network)
+	if err != nil {
+		return nil, nil, err
+	}
+	return network, networks, nil
+}
+
+// prepare allocates the address of record if it has none, derives its AllowedIPs if needed,
+// and checks the result against the other peers of its network, which is returned
+func (a *ipam) prepare(store PeerStore, record *PeerRecord) (*NetworkRecord, error) {
+	network, networks, err := getNetwork(store, record.Network)
+	if err != nil {
+		return nil, err
+	}
+	others, err := store.ListPeers(record.Network)
+	if err != nil {
+		return nil, err
+	}
+	if record.Address == "" {
+		if err = allocate(networks, record, others); err != nil {
+			return nil, &ipamError{code: codes.FailedPrecondition, err: err}
+		}
+	}
+	if record.AllowedIPs == "" {
+		record.AllowedIPs = derivedAllowedIPs(record.Address)
+	}
+	if err = checkNetworks(networks, record); err != nil {
+		return nil, &ipamError{code: codes.InvalidArgument, err: err}
+	}
+	if err = checkConflicts(record, others); err != nil {
+		return nil, &ipamError{code: codes.AlreadyExists, err: err}
+	}
+	if err = checkRoutes(networks, record, others); err != nil {
+		return nil, &ipamError{code: codes.AlreadyExists, err: err}
+	}
+	return network, nil
+}
+
+// createPeer allocates the address of record and saves it,
+// with the listen port and PersistentKeepalive of its network unless set
+func (a *ipam) createPeer(store PeerStore, record *PeerRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	network, err := a.prepare(store, record)
+	if err != nil {
+		return err
+	}
+	if record.ListenPort == 0 {
+		record.ListenPort = network.ListenPort
+	}
+	if record.ListenPort == 0 {
+		record.ListenPort = defaultListenPort
+	}
+	if record.PersistentKeepalive == 0 {
+		record.PersistentKeepalive = network.PersistentKeepalive
+	}
+	return store.CreatePeer(record)
+}
+
+// updatePeer saves record, deriving its AllowedIPs again if they were derived from the address it replaces
+func (a *ipam) updatePeer(store PeerStore, record *PeerRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	current, err := store.GetPeer(record.ID)
+	if err != nil {
+		return err
+	}
+	if record.Address != current.Address && record.AllowedIPs != "" &&
+		normalizeCIDRList(record.AllowedIPs) == normalizeCIDRList(derivedAllowedIPs(current.Address)) {
+		// the other peers would route the new address nowhere
+		record.AllowedIPs = ""
+	}
+	if _, err = a.prepare(store, record); err != nil {
+		return err
+	}
+	return store.UpdatePeer(record)
+}
+
+// updateNetwork saves network, rejecting new CIDRs which would leave some of its peers out
+func (a *ipam) updateNetwork(store PeerStore, network *NetworkRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	networks, err := parseNetworks(network)
+	if err != nil {
+		return &ipamError{code: codes.InvalidArgument, err: err}
+	}
+	peers, err := store.ListPeers(network.Name)
+	if err != nil {
+		return err
+	}
+	for _, peer := range peers {
+		if err = checkNetworks(networks, peer); err != nil {
+			return &ipamError{code: codes.FailedPrecondition, err: fmt.Errorf("peer %s: %w", peer.Hostname, err)}
+		}
+	}
+	return store.UpdateNetwork(network)
+}
+
+// deleteNetwork deletes the network named name, which must have no peer nor policy
+func (a *ipam) deleteNetwork(store PeerStore, name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	peers, err := store.ListPeers(name)
+	if err != nil {
+		return err
+	}
+	if len(peers) > 0 {
+		return &ipamError{code: codes.FailedPrecondition, err: fmt.Errorf("network %s still has %d peers", name, len(peers))}
+	}
+	policies, err := store.ListPolicies(name)
+	if err != nil {
+		return err
+	}
+	if len(policies) > 0 {
+		return &ipamError{code: codes.FailedPrecondition, err: fmt.Errorf("network %s still has %d policies", name, len(policies))}
+	}
+	return store.DeleteNetwork(name)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+// newIPAMStore returns a store with the dual-stack default network
+func newIPAMStore(t *testing.T) *memoryStore {
+	t.Helper()
+	store := newMemoryStore()
+	if err := ensureDefaultNetwork(store, NetworkConfig{CIDR: "10.0.0.0/30", CIDR6: "fd00::/64"}); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestIPAMAllocate(t *testing.T) {
+	store, a := newIPAMStore(t), newIPAM()
+	first := &PeerRecord{Network: "default", Hostname: "a"}
+	if err := a.createPeer(store, first); err != nil {
+		t.Fatal(err)
+	}
+	// the network address is skipped, and the AllowedIPs are the host addresses
+	if first.Address != "10.0.0.1/30, fd00::1/64" || first.AllowedIPs != "10.0.0.1/32, fd00::1/128" {
+		t.Errorf("first peer = %s, %s", first.Address, first.AllowedIPs)
+	}
+	if first.ListenPort != defaultListenPort {
+		t.Errorf("listen port = %d", first.ListenPort)
+	}
+	second := &PeerRecord{Network: "default", Hostname: "b"}
+	if err := a.createPeer(store, second); err != nil {
+		t.Fatal(err)
+	}
+	if second.Address != "10.0.0.2/30, fd00::2/64" {
+		t.Errorf("second peer = %s", second.Address)
+	}
+	// 10.0.0.3 is the broadcast address
+	if err := a.createPeer(store, &PeerRecord{Network: "default", Hostname: "c"}); ipamCode(err) != codes.FailedPrecondition {
+		t.Errorf("allocate in a full network: %v", err)
+	}
+	if err := a.createPeer(store, &PeerRecord{Network: "lab", Hostname: "c"}); ipamCode(err) != codes.InvalidArgument {
+		t.Errorf("allocate in an unknown network: %v", err)
+	}
+}
+
+func TestIPAMConflicts(t *testing.T) {
+	store, a := newIPAMStore(t), newIPAM()
+	if err := a.createPeer(store, &PeerRecord{Network: "default", Hostname: "a", Address: "10.0.0.1/30", Routes: "192.168.1.0/24"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name   string
+		record *PeerRecord
+		code   codes.Code
+	}{
+		{"used address", &PeerRecord{Address: "10.0.0.1/30"}, codes.AlreadyExists},
+		{"out of the network", &PeerRecord{Address: "10.1.0.1/24"}, codes.InvalidArgument},
+		{"overlapping allowedIPs", &PeerRecord{Address: "10.0.0.2/30", AllowedIPs: "10.0.0.0/30"}, codes.AlreadyExists},
+		{"allowedIPs on a route", &PeerRecord{Address: "10.0.0.2/30", AllowedIPs: "10.0.0.2/32, 192.168.1.1/32"}, codes.AlreadyExists},
+		{"route on the network", &PeerRecord{Address: "10.0.0.2/30", Routes: "10.0.0.0/24"}, codes.AlreadyExists},
+	} {
+		test.record.Network, test.record.Hostname = "default", "b"
+		if err := a.createPeer(store, test.record); ipamCode(err) != test.code {
+			t.Errorf("%s: %v, want %s", test.name, err, test.code)
+		}
+	}
+}
+
+func TestIPAMUpdateAddress(t *testing.T) {
+	store, a := newIPAMStore(t), newIPAM()
+	record := &PeerRecord{Network: "default", Hostname: "a", Address: "10.0.0.1/30"}
+	if err := a.createPeer(store, record); err != nil {
+		t.Fatal(err)
+	}
+	// the AllowedIPs derived from the previous address follow it
+	record.Address = "10.0.0.2/30"
+	if err := a.updatePeer(store, record); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := store.GetPeer(record.ID); stored.AllowedIPs != "10.0.0.2/32" {
+		t.Errorf("allowedIPs after moving the address = %s", stored.AllowedIPs)
+	}
+	// the ones set by the admin are kept
+	record.AllowedIPs = "10.0.0.2/32, 172.16.0.0/16"
+	if err := a.updatePeer(store, record); err != nil {
+		t.Fatal(err)
+	}
+	record.Address = "10.0.0.1/30"
+	if err := a.updatePeer(store, record); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := store.GetPeer(record.ID); stored.AllowedIPs != "10.0.0.2/32, 172.16.0.0/16" {
+		t.Errorf("allowedIPs set by the admin = %s", stored.AllowedIPs)
+	}
+}
+
+// ipamCode returns the gRPC code of the errors of ipam
+func ipamCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if e, ok := err.(*ipamError); ok {
+		return e.code
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+const keyLen = curve25519.ScalarSize
+
+// generatePrivateKey returns a new base64 encoded Curve25519 private key,
+// clamped the same way as `wg genkey` does.
+func generatePrivateKey() (string, error) {
+	key := make([]byte, keyLen)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	key[0] &= 248
+	key[31] = (key[31] & 127) | 64
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// publicKeyOf derives the base64 encoded public key from a base64 encoded private key.
+func publicKeyOf(privateKey string) (string, error) {
+	key, err := decodeKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	pub, err := curve25519.X25519(key, curve25519.Basepoint)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), nil
+}
+
+func decodeKey(key string) ([]byte, error) {
+	bytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	if len(bytes) != keyLen {
+		return nil, fmt.Errorf("invalid key length: %d", len(bytes))
+	}
+	return bytes, nil
+}
+
+func validateKey(key string) error {
+	_, err := decodeKey(key)
+	return err
+}
+
+// loadOrCreatePrivateKey reads the private key stored in filename,
+// or generates and persists a new one if the file does not exist yet.
+func loadOrCreatePrivateKey(filename string) (string, error) {
+	content, err := readFile(filename)
+	if err == nil {
+		privateKey := strings.TrimSpace(content)
+		if err = validateKey(privateKey); err != nil {
+			return "", fmt.Errorf("%s: %w", filename, err)
+		}
+		return privateKey, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	privateKey, err := generatePrivateKey()
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(filename, []byte(privateKey+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	defaultOfflineAfter = 3 * time.Minute
+	// reportInterval is how often the client reports its status while it follows the network stream
+	reportInterval = time.Minute
+)
+
+// LivenessConfig tells when a silent peer is offline
+type LivenessConfig struct {
+	// OfflineAfter is how long a peer can stay silent before it is offline, 3m by default
+	OfflineAfter time.Duration `yaml:"offlineAfter"`
+	// ExcludeOffline leaves the offline peers out of the networks of the others
+	ExcludeOffline bool `yaml:"excludeOffline"`
+}
+
+func (c LivenessConfig) offlineAfter() time.Duration {
+	if c.OfflineAfter <= 0 {
+		return defaultOfflineAfter
+	}
+	return c.OfflineAfter
+}
+
+// online reports whether record was seen within OfflineAfter of now
+func (c LivenessConfig) online(record *PeerRecord, now time.Time) bool {
+	return record.LastSeen > 0 && now.Sub(time.Unix(record.LastSeen, 0)) < c.offlineAfter()
+}
+
+// onlineRecords returns the records online at now, or all of them unless ExcludeOffline
+func (c LivenessConfig) onlineRecords(records []*PeerRecord, now time.Time) []*PeerRecord {
+	if !c.ExcludeOffline {
+		return records
+	}
+	var online []*PeerRecord
+	for _, record := range records {
+		if c.online(record, now) {
+			online = append(online, record)
+		}
+	}
+	return online
+}
+
+// markSeen records that the peer of self is alive, running clientVersion in status.
+// The other peers are notified when an excluded peer comes back online.
+func (s *server) markSeen(self *PeerRecord, clientVersion, status string) {
+	// the columns of the mysql store are bounded
+	clientVersion, status = truncate(clientVersion, 64), truncate(status, 255)
+	now := time.Now()
+	wasOnline := s.settings.liveness().online(self, now)
+	if err := s.store.UpdatePeerSeen(self.ID, now.Unix(), clientVersion, status); err != nil {
+		logger.withPeer(self).error("record when the peer was seen", "err", err)
+		return
+	}
+	if !wasOnline {
+		logger.withPeer(self).info("peer is online", "version", clientVersion)
+		if s.settings.liveness().ExcludeOffline {
+			s.broadcaster.notify()
+		}
+	}
+	self.LastSeen, self.ClientVersion, self.Status = now.Unix(), clientVersion, status
+}
+
+func truncate(value string, size int) string {
+	if len(value) > size {
+		return strings.ToValidUTF8(value[:size], "")
+	}
+	return value
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// LogConfig tells how the server and the client log
+type LogConfig struct {
+	// Level is the lowest level logged: debug, info (default), warn or error
+	Level string `yaml:"level"`
+	// Format is text, logfmt lines (default), or json, one object per line
+	Format string `yaml:"format"`
+}
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+func (level logLevel) String() string {
+	return logLevelNames[level]
+}
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logOutput is where every logger writes, set once from the config
+var logOutput = struct {
+	mu     sync.Mutex
+	w      io.Writer
+	level  logLevel
+	format string
+}{w: os.Stderr, level: levelInfo, format: logFormatText}
+
+// configureLogging applies conf to every logger
+func configureLogging(conf LogConfig) error {
+	level := levelInfo
+	if conf.Level != "" {
+		found := false
+		for i, name := range logLevelNames {
+			if strings.EqualFold(conf.Level, name) {
+				level, found = logLevel(i), true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown log level %q, expect debug, info, warn or error", conf.Level)
+		}
+	}
+	format := strings.ToLower(conf.Format)
+	switch format {
+	case "":
+		format = logFormatText
+	case logFormatText, logFormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q, expect text or json", conf.Format)
+	}
+	logOutput.mu.Lock()
+	defer logOutput.mu.Unlock()
+	logOutput.level, logOutput.format = level, format
+	return nil
+}
+
+// fieldLogger writes leveled records carrying its fields, such as the peer or the request they are about.
+// The fields, as those given to each record, are key-value pairs. A nil fieldLogger has no fields.
+type fieldLogger struct {
+	fields []interface{}
+}
+
+// logger is the root logger, without fields
+var logger = &fieldLogger{}
+
+// with returns a logger adding keyValues to the fields of l
+func (l *fieldLogger) with(keyValues ...interface{}) *fieldLogger {
+	if l == nil {
+		l = logger
+	}
+	fields := make([]interface{}, 0, len(l.fields)+len(keyValues))
+	fields = append(fields, l.fields...)
+	return &fieldLogger{fields: append(fields, keyValues...)}
+}
+
+func (l *fieldLogger) debug(msg string, keyValues ...interface{}) {
+	l.write(levelDebug, msg, keyValues)
+}
+
+func (l *fieldLogger) info(msg string, keyValues ...interface{}) {
+	l.write(levelInfo, msg, keyValues)
+}
+
+func (l *fieldLogger) warn(msg string, keyValues ...interface{}) {
+	l.write(levelWarn, msg, keyValues)
+}
+
+func (l *fieldLogger) error(msg string, keyValues ...interface{}) {
+	l.write(levelError, msg, keyValues)
+}
+
+// withPeer returns a logger with the fields identifying record
+func (l *fieldLogger) withPeer(record *PeerRecord) *fieldLogger {
+	return l.with("peer", record.ID, "hostname", record.Hostname, "network", record.Network)
+}
+
+// fatal logs at the error level and exits
+func (l *fieldLogger) fatal(msg string, keyValues ...interface{}) {
+	l.write(levelError, msg, keyValues)
+	os.Exit(1)
+}
+
+func (l *fieldLogger) write(level logLevel, msg string, keyValues []interface{}) {
+	logOutput.mu.Lock()
+	defer logOutput.mu.Unlock()
+	if level < logOutput.level {
+		return
+	}
+	if l == nil {
+		l = logger
+	}
+	fields := make([]interface{}, 0, 6+len(l.fields)+len(keyValues))
+	fields = append(fields, "time", time.Now().Format("2006-01-02T15:04:05.000Z07:00"), "level", level.String(), "msg", msg)
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyValues...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "(missing)")
+	}
+	var line string
+	if logOutput.format == logFormatJSON {
+		line = encodeJSONFields(fields)
+	} else {
+		line = encodeTextFields(fields)
+	}
+	_, _ = io.WriteString(logOutput.w, line+"\n")
+}
+
+// fieldValue turns the errors and the stringers into strings
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+// encodeTextFields writes logfmt: key=value, the values with spaces or quotes being quoted
+func encodeTextFields(fields []interface{}) string {
+	pairs := make([]string, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		value := fmt.Sprint(fieldValue(fields[i+1]))
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, fmt.Sprintf("%v=%s", fields[i], value))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func encodeJSONFields(fields []interface{}) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(fmt.Sprint(fields[i]))
+		value, err := json.Marshal(fieldValue(fields[i+1]))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(fields[i+1]))
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+type requestLoggerKey struct{}
+
+// loggerFrom returns the logger of the request of ctx, with its request id, or the root logger
+func loggerFrom(ctx context.Context) *fieldLogger {
+	if l, ok := ctx.Value(requestLoggerKey{}).(*fieldLogger); ok {
+		return l
+	}
+	return logger
+}
+
+// withRequestLogger gives the request of ctx a logger with method and requestID,
+// the id sent by the caller if any, or a new one
+func withRequestLogger(ctx context.Context, requestID, method string) context.Context {
+	requestID = truncate(requestID, 64)
+	if requestID == "" {
+		id := make([]byte, 8)
+		_, _ = rand.Read(id)
+		requestID = hex.EncodeToString(id)
+	}
+	return context.WithValue(ctx, requestLoggerKey{}, logger.with("request_id", requestID, "method", method))
+}
+
+// withGrpcRequestLogger reads the request id in the x-request-id metadata
+func withGrpcRequestLogger(ctx context.Context, method string) context.Context {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-request-id"); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	return withRequestLogger(ctx, requestID, method)
+}
+
+func requestLoggerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withGrpcRequestLogger(ctx, info.FullMethod), req)
+}
+
+// loggedStream overrides the context of a stream with the one carrying the request logger
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+func requestLoggerStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &loggedStream{ServerStream: stream, ctx: withGrpcRequestLogger(stream.Context(), info.FullMethod)})
+}
+
+// httpRequestLogger gives every request a logger, with the id in its X-Request-Id header if any
+func httpRequestLogger(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := withRequestLogger(r.Context(), r.Header.Get("X-Request-Id"), r.Method+" "+r.URL.Path)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,28 +1,82 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
-func main() {
-	args := os.Args
-	if len(args) < 3 {
-		panic("no enough args")
+// version is set at build time, see Makefile
+var version = "dev"
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+func commandList() []*command {
+	return []*command{
+		{"server", "run the server, or manage its store", serverCommand},
+		{"client", "run the client, keeping the WireGuard interface in sync with the server", clientCommand},
+		{"peer", "manage the peers through the admin API of the server", peerCommand},
+		{"network", "manage the networks through the admin API of the server", networkCommand},
+		{"policy", "manage the policies through the admin API of the server", policyCommand},
+		{"status", "show the local state of the client", statusCommand},
+		{"version", "print the version", versionCommand},
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: wukuard <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commandList() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.description)
 	}
+	fmt.Fprintf(w, "\nRun 'wukuard <command> -h' for the flags of a command.\n")
+}
 
-	switch strings.ToLower(args[1]) {
-	case "server":
-		confPath := args[2]
-		serverMain(confPath)
-	case "client":
-		serverAddr := args[2]
-		inputInterfaceName := ""
-		if len(args) > 3 {
-			inputInterfaceName = args[3]
+// run executes the command in args and returns the exit code:
+// 0 on success, 1 if the command failed and 2 if it was misused
+func run(args []string) int {
+	if len(args) < 1 {
+		usage(os.Stderr)
+		return 2
+	}
+	name := strings.ToLower(args[0])
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage(os.Stdout)
+		return 0
+	}
+	for _, cmd := range commandList() {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			if err != errUsage {
+				// a bare errUsage has already been reported by the flag package
+				fmt.Fprintf(os.Stderr, "wukuard %s: %s\n", name, err.Error())
+			}
+			return 2
+		default:
+			fmt.Fprintf(os.Stderr, "wukuard %s: %s\n", name, err.Error())
+			return 1
 		}
-		clientMain(serverAddr, inputInterfaceName)
-	default:
-		panic("unknown action")
 	}
+	fmt.Fprintf(os.Stderr, "wukuard: unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return 2
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// the metrics of the server and of the client are kept apart, so that each one only exposes its own
+var (
+	serverMetrics = newMetricsRegistry()
+	clientMetrics = newMetricsRegistry()
+)
+
+// newMetricsRegistry returns a registry with the metrics of the Go runtime and of the process
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return registry
+}
+
+// serveMetrics exposes the metrics of registry on addr until the returned server is shut down,
+// along with the health checks if any
+func serveMetrics(addr string, registry *prometheus.Registry, healthChecks http.Handler) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	if healthChecks != nil {
+		mux.Handle("/healthz", healthChecks)
+		mux.Handle("/readyz", healthChecks)
+	}
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		logger.info("metrics listening", "addr", addr)
+		if err := srv.Serve(lis); err != http.ErrServerClosed {
+			logger.fatal("failed to serve metrics", "err", err)
+		}
+	}()
+	return srv, nil
+}
+
+// the metrics of the server
+var (
+	heartbeatsTotal = promauto.With(serverMetrics).NewCounterVec(prometheus.CounterOpts{
+		Name: "wukuard_heartbeats_total",
+		Help: "Heartbeats served, per peer.",
+	}, []string{"network", "peer"})
+	heartbeatDuration = promauto.With(serverMetrics).NewHistogram(prometheus.HistogramOpts{
+		Name: "wukuard_heartbeat_duration_seconds",
+		Help: "Time taken to serve a heartbeat.",
+	})
+	unknownPeersTotal = promauto.With(serverMetrics).NewCounter(prometheus.CounterOpts{
+		Name: "wukuard_unknown_peer_lookups_total",
+		Help: "Requests from clients whose credential matches no peer.",
+	})
+	storeErrorsTotal = promauto.With(serverMetrics).NewCounterVec(prometheus.CounterOpts{
+		Name: "wukuard_store_errors_total",
+		Help: "Failed operations of the store, such as DB queries, per operation.",
+	}, []string{"operation"})
+	watchers = promauto.With(serverMetrics).NewGauge(prometheus.GaugeOpts{
+		Name: "wukuard_watchers",
+		Help: "Clients following their network through a stream.",
+	})
+)
+
+// the metrics of the client
+var (
+	clientAppliesTotal = promauto.With(clientMetrics).NewCounterVec(prometheus.CounterOpts{
+		Name: "wukuard_client_applies_total",
+		Help: "Networks applied to the interface, per result: ok, error or unchanged.",
+	}, []string{"interface", "result"})
+	clientRestartsTotal = promauto.With(clientMetrics).NewCounterVec(prometheus.CounterOpts{
+		Name: "wukuard_client_restarts_total",
+		Help: "Restarts of the wg-quick service.",
+	}, []string{"interface"})
+	clientLastSync = promauto.With(clientMetrics).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "wukuard_client_last_sync_timestamp_seconds",
+		Help: "When the interface was last found in sync with the server, in unix seconds.",
+	}, []string{"interface"})
+	clientHandshakeAge = prometheus.NewDesc("wukuard_client_peer_handshake_age_seconds",
+		"Seconds since the latest handshake with each peer, left out before the first one.", []string{"interface", "peer"}, nil)
+)
+
+// Describe and Collect expose the stats of the tunnels of the running agents, read on every scrape
+func (c *client) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clientHandshakeAge
+}
+
+func (c *client) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	agents := c.agents
+	c.mu.Unlock()
+	for _, a := range agents {
+		a.collectStats(ch)
+	}
+}
+
+// collectStats sends the metrics of the tunnels of the agent to ch
+func (a *agent) collectStats(ch chan<- prometheus.Metric) {
+	a.mu.Lock()
+	stats, err := a.backend.Stats()
+	a.mu.Unlock()
+	if err != nil {
+		a.log.warn("read the stats of the device", "err", err)
+		return
+	}
+	now := time.Now()
+	for _, peerStats := range stats {
+		if !peerStats.LastHandshake.IsZero() {
+			ch <- prometheus.MustNewConstMetric(clientHandshakeAge, prometheus.GaugeValue,
+				now.Sub(peerStats.LastHandshake).Seconds(), a.conf.Interface, peerStats.PublicKey)
+		}
+	}
+}
+
+// countingStore counts the failed operations of a store, not finding a record being no failure
+type countingStore struct {
+	PeerStore
+}
+
+func (s *countingStore) count(operation string, err error) error {
+	if err != nil && !errors.Is(err, errNotFound) {
+		storeErrorsTotal.WithLabelValues(operation).Inc()
+	}
+	return err
+}
+
+func (s *countingStore) GetNetwork(name string) (*NetworkRecord, error) {
+	record, err := s.PeerStore.GetNetwork(name)
+	return record, s.count("GetNetwork", err)
+}
+
+func (s *countingStore) ListNetworks() ([]*NetworkRecord, error) {
+	records, err := s.PeerStore.ListNetworks()
+	return records, s.count("ListNetworks", err)
+}
+
+func (s *countingStore) CreateNetwork(record *NetworkRecord) error {
+	return s.count("CreateNetwork", s.PeerStore.CreateNetwork(record))
+}
+
+func (s *countingStore) UpdateNetwork(record *NetworkRecord) error {
+	return s.count("UpdateNetwork", s.PeerStore.UpdateNetwork(record))
+}
+
+func (s *countingStore) DeleteNetwork(name string) error {
+	return s.count("DeleteNetwork", s.PeerStore.DeleteNetwork(name))
+}
+
+func (s *countingStore) GetPeer(id int32) (*PeerRecord, error) {
+	record, err := s.PeerStore.GetPeer(id)
+	return record, s.count("GetPeer", err)
+}
+
+func (s *countingStore) GetPeerByToken(tokenHash string) (*PeerRecord, error) {
+	record, err := s.PeerStore.GetPeerByToken(tokenHash)
+	return record, s.count("GetPeerByToken", err)
+}
+
+func (s *countingStore) GetPeerByHostname(network, hostname string) (*PeerRecord, error) {
+	record, err := s.PeerStore.GetPeerByHostname(network, hostname)
+	return record, s.count("GetPeerByHostname", err)
+}
+
+func (s *countingStore) ListPeers(network string) ([]*PeerRecord, error) {
+	records, err := s.PeerStore.ListPeers(network)
+	return records, s.count("ListPeers", err)
+}
+
+func (s *countingStore) CreatePeer(record *PeerRecord) error {
+	return s.count("CreatePeer", s.PeerStore.CreatePeer(record))
+}
+
+func (s *countingStore) UpdatePeer(record *PeerRecord) error {
+	return s.count("UpdatePeer", s.PeerStore.UpdatePeer(record))
+}
+
+func (s *countingStore) DeletePeer(id int32) error {
+	return s.count("DeletePeer", s.PeerStore.DeletePeer(id))
+}
+
+func (s *countingStore) WasPeerDeleted(tokenHash string) (bool, error) {
+	deleted, err := s.PeerStore.WasPeerDeleted(tokenHash)
+	return deleted, s.count("WasPeerDeleted", err)
+}
+
+func (s *countingStore) WasHostnameDeleted(network, hostname string) (bool, error) {
+	deleted, err := s.PeerStore.WasHostnameDeleted(network, hostname)
+	return deleted, s.count("WasHostnameDeleted", err)
+}
+
+func (s *countingStore) UpdatePeerEndpoint(id int32, endpoint, publicEndpoint string) error {
+	return s.count("UpdatePeerEndpoint", s.PeerStore.UpdatePeerEndpoint(id, endpoint, publicEndpoint))
+}
+
+func (s *countingStore) UpdatePeerSeen(id int32, lastSeen int64, clientVersion, status string) error {
+	return s.count("UpdatePeerSeen", s.PeerStore.UpdatePeerSeen(id, lastSeen, clientVersion, status))
+}
+
+func (s *countingStore) UpdatePeerStats(id int32, stats []*PeerStatsRecord) error {
+	return s.count("UpdatePeerStats", s.PeerStore.UpdatePeerStats(id, stats))
+}
+
+func (s *countingStore) ListPeerStats(id int32) ([]*PeerStatsRecord, error) {
+	records, err := s.PeerStore.ListPeerStats(id)
+	return records, s.count("ListPeerStats", err)
+}
+
+func (s *countingStore) UpdatePeerPublicKey(id int32, publicKey string) error {
+	return s.count("UpdatePeerPublicKey", s.PeerStore.UpdatePeerPublicKey(id, publicKey))
+}
+
+func (s *countingStore) UpdatePeerToken(id int32, tokenHash, publicKey string) error {
+	return s.count("UpdatePeerToken", s.PeerStore.UpdatePeerToken(id, tokenHash, publicKey))
+}
+
+func (s *countingStore) GetPolicy(id int32) (*PolicyRecord, error) {
+	record, err := s.PeerStore.GetPolicy(id)
+	return record, s.count("GetPolicy", err)
+}
+
+func (s *countingStore) ListPolicies(network string) ([]*PolicyRecord, error) {
+	records, err := s.PeerStore.ListPolicies(network)
+	return records, s.count("ListPolicies", err)
+}
+
+func (s *countingStore) CreatePolicy(record *PolicyRecord) error {
+	return s.count("CreatePolicy", s.PeerStore.CreatePolicy(record))
+}
+
+func (s *countingStore) UpdatePolicy(record *PolicyRecord) error {
+	return s.count("UpdatePolicy", s.PeerStore.UpdatePolicy(record))
+}
+
+func (s *countingStore) DeletePolicy(id int32) error {
+	return s.count("DeletePolicy", s.PeerStore.DeletePolicy(id))
+}
+
+func (s *countingStore) CreateJoinToken(token *JoinToken) error {
+	return s.count("CreateJoinToken", s.PeerStore.CreateJoinToken(token))
+}
+
+func (s *countingStore) ConsumeJoinToken(tokenHash string, now int64) (*JoinToken, error) {
+	token, err := s.PeerStore.ConsumeJoinToken(tokenHash, now)
+	return token, s.count("ConsumeJoinToken", err)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// scrape returns the metrics of registry as served on /metrics
+func scrape(t *testing.T, registry *prometheus.Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestServerMetrics(t *testing.T) {
+	n := newTestNetwork(t)
+	n.addPeer("default", "metrics-a", "10.0.0.1/24")
+	unknown := testutil.ToFloat64(unknownPeersTotal)
+	storeErrors := testutil.ToFloat64(storeErrorsTotal.WithLabelValues("ListPeers"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := n.heartBeat(&pb.PeerRequest{Credential: "metrics-a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := n.heartBeat(&pb.PeerRequest{Credential: "unknown"}); err == nil {
+		t.Fatal("unknown credential accepted")
+	}
+	if got := testutil.ToFloat64(unknownPeersTotal) - unknown; got != 1 {
+		t.Errorf("unknown peer lookups = %v, want 1", got)
+	}
+
+	// not finding a record is no failure of the store
+	store := &countingStore{PeerStore: failingStore{PeerStore: n.store, failing: "peers"}}
+	_, _ = store.GetPeer(42)
+	_, _ = store.ListPeers("default")
+	if got := testutil.ToFloat64(storeErrorsTotal.WithLabelValues("ListPeers")) - storeErrors; got != 1 {
+		t.Errorf("store errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(storeErrorsTotal.WithLabelValues("GetPeer")); got != 0 {
+		t.Errorf("store errors of GetPeer = %v, want 0", got)
+	}
+
+	body := scrape(t, serverMetrics)
+	for _, want := range []string{
+		"# TYPE wukuard_heartbeats_total counter",
+		`wukuard_heartbeats_total{network="default",peer="metrics-a"} 2`,
+		"# TYPE wukuard_heartbeat_duration_seconds histogram",
+		`wukuard_heartbeat_duration_seconds_bucket{le="+Inf"}`,
+		"wukuard_watchers 0",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("no %q in /metrics:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "wukuard_client_") {
+		t.Error("the server exposes the metrics of the client")
+	}
+}
+
+// statsBackend reports the stats it is given
+type statsBackend struct {
+	WireGuardBackend
+	stats []*PeerStats
+}
+
+func (b *statsBackend) Stats() ([]*PeerStats, error) {
+	return b.stats, nil
+}
+
+func TestClientMetrics(t *testing.T) {
+	backend := &statsBackend{stats: []*PeerStats{
+		{PublicKey: "peer-a", LastHandshake: time.Now().Add(-time.Minute)},
+		{PublicKey: "peer-b"},
+	}}
+	c := &client{agents: []*agent{{conf: &ClientConfig{Interface: `wk"0`}, backend: backend}}}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	body := scrape(t, registry)
+	// the label values are escaped, and the peers without handshake left out
+	if !strings.Contains(body, `wukuard_client_peer_handshake_age_seconds{interface="wk\"0",peer="peer-a"} 60`) {
+		t.Errorf("no handshake age of peer-a in /metrics:\n%s", body)
+	}
+	if strings.Contains(body, "peer-b") {
+		t.Errorf("peer-b reported before its first handshake:\n%s", body)
+	}
+
+	c.agents = nil
+	if body = scrape(t, registry); strings.Contains(body, "wukuard_client_peer_handshake_age_seconds{") {
+		t.Errorf("the stats of stopped agents are still exposed:\n%s", body)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrations are applied in the order of the version prefix of their filename,
+// e.g. 0001_create_wukuard.sql. Never edit an applied migration, add a new one instead.
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration filename: %s", name)
+		}
+		content, err := migrationFS.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(content)})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// splitStatements splits a migration into statements, as the driver runs one statement at a time.
+// The semicolons in quoted strings, quoted identifiers and comments don't end a statement,
+// and the statements made of comments only are dropped.
+func splitStatements(content string) []string {
+	var statements []string
+	start, code := 0, false
+	add := func(end int) {
+		if code {
+			statements = append(statements, strings.TrimSpace(content[start:end]))
+		}
+	}
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\'', c == '"', c == '`':
+			code = true
+			// a quote is escaped by a backslash, or by doubling it
+			for i++; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		case c == '#', c == '-' && strings.HasPrefix(content[i:], "-- "), c == '-' && strings.HasPrefix(content[i:], "--\n"):
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+		case c == ';':
+			add(i)
+			start, code = i+1, false
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			code = true
+		}
+	}
+	add(len(content))
+	return statements
+}
+
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+	_, err := db.Exec(`create table if not exists wukuard_schema_migration
+(
+    version    int primary key,
+    name       varchar(255) not null,
+    applied_at bigint       not null
+)`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query("select version from wukuard_schema_migration")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions[version] = true
+	}
+	return versions, rows.Err()
+}
+
+// migrateDB applies the embedded migrations which are not applied yet
+func migrateDB(db *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		for _, statement := range splitStatements(m.sql) {
+			if _, err = db.Exec(statement); err != nil {
+				return fmt.Errorf("migration %s: %w", m.name, err)
+			}
+		}
+		_, err = db.Exec("insert into wukuard_schema_migration (version, name, applied_at) values (?, ?, ?)",
+			m.version, m.name, time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+		logger.info("applied migration", "migration", m.name)
+	}
+	return nil
+}
+
+// migrator is implemented by the stores with a schema
+type migrator interface {
+	Migrate() error
+}
+
+// migrateStore migrates store, which must be the store opened rather than one of the wrappers of the server
+func migrateStore(store PeerStore) error {
+	if m, ok := store.(migrator); ok {
+		return m.Migrate()
+	}
+	return nil
+}
+
+// migrateMain applies the schema migrations and exits
+func migrateMain(confPath string) error {
+	_, store, err := openServerStore(confPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err = migrateStore(store); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	logger.info("schema is up to date")
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	for _, test := range []struct {
+		content string
+		want    []string
+	}{
+		{"create table a (id int);\ncreate table b (id int);\n", []string{"create table a (id int)", "create table b (id int)"}},
+		{"insert into a values ('x;y', \"it\\\"s;\", 'it''s;')", []string{"insert into a values ('x;y', \"it\\\"s;\", 'it''s;')"}},
+		{"alter table `a;b` add c int comment 'c; d';", []string{"alter table `a;b` add c int comment 'c; d'"}},
+		{"-- drop a; later\ncreate table a (id int); # b;\n/* c; */ create table c (id int);\n-- the end;\n", []string{
+			"-- drop a; later\ncreate table a (id int)",
+			"# b;\n/* c; */ create table c (id int)",
+		}},
+		{"select 1 - -1;", []string{"select 1 - -1"}},
+		{" ;\n; ", nil},
+	} {
+		if got := splitStatements(test.content); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitStatements(%q) = %q, want %q", test.content, got, test.want)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("%s: version %d, want %d", m.name, m.version, i+1)
+		}
+		if len(splitStatements(m.sql)) <= 0 {
+			t.Errorf("%s: no statement", m.name)
+		}
+	}
+}
+
+// migratingStore counts its migrations
+type migratingStore struct {
+	PeerStore
+	migrations int
+}
+
+func (s *migratingStore) Migrate() error {
+	s.migrations++
+	return nil
+}
+
+func TestMigrateStore(t *testing.T) {
+	store := &migratingStore{PeerStore: newMemoryStore()}
+	if err := migrateStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if store.migrations != 1 {
+		t.Errorf("migrations = %d, want 1", store.migrations)
+	}
+	// the memory store has no schema
+	if err := migrateStore(newMemoryStore()); err != nil {
+		t.Error(err)
+	}
+}
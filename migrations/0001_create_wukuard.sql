@@ -0,0 +1,18 @@
+create table if not exists wukuard
+(
+    id                   int auto_increment primary key,
+    mac_address          varchar(64)  null,
+    hostname             varchar(255) not null,
+    token                varchar(255) null,
+    public_key           varchar(64)  not null default '',
+    private_key          varchar(64)  not null default '',
+    post_up              varchar(1024) not null default '',
+    pre_down             varchar(1024) not null default '',
+    address              varchar(255) not null default '',
+    listen_port          int          not null default 0,
+    endpoint             varchar(255) not null default '',
+    allowed_ips          varchar(1024) not null default '',
+    persistent_keepalive int          not null default 0,
+    created_at           bigint       not null default 0,
+    updated_at           bigint       not null default 0
+);
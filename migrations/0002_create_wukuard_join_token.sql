@@ -0,0 +1,9 @@
+create table if not exists wukuard_join_token
+(
+    id         int auto_increment primary key,
+    token_hash char(64)     not null unique,
+    hostname   varchar(255) not null,
+    expires_at bigint       not null,
+    used_at    bigint       null,
+    created_at bigint       not null
+);
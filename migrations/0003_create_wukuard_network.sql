@@ -0,0 +1,19 @@
+create table if not exists wukuard_network
+(
+    id                   int auto_increment primary key,
+    name                 varchar(64)  not null unique,
+    cidr                 varchar(64)  not null default '',
+    cidr6                varchar(64)  not null default '',
+    dns                  varchar(255) not null default '',
+    listen_port          int          not null default 0,
+    persistent_keepalive int          not null default 0,
+    created_at           bigint       not null default 0,
+    updated_at           bigint       not null default 0
+);
+
+-- the peers and tokens created before networks belong to the default one
+alter table wukuard
+    add column network varchar(64) not null default 'default' after id;
+
+alter table wukuard_join_token
+    add column network varchar(64) not null default 'default' after token_hash;
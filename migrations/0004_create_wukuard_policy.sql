@@ -0,0 +1,14 @@
+create table if not exists wukuard_policy
+(
+    id          int auto_increment primary key,
+    network     varchar(64)   not null default 'default',
+    description varchar(255)  not null default '',
+    from_tags   varchar(1024) not null default '',
+    to_tags     varchar(1024) not null default '',
+    cidrs       varchar(1024) not null default '',
+    created_at  bigint        not null default 0,
+    updated_at  bigint        not null default 0
+);
+
+alter table wukuard
+    add column tags varchar(1024) not null default '' after persistent_keepalive;
@@ -0,0 +1,3 @@
+alter table wukuard
+    add column relay      tinyint(1) not null default 0 after tags,
+    add column relayed_by int        not null default 0 after relay;
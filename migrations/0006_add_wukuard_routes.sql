@@ -0,0 +1,3 @@
+alter table wukuard
+    add column routes      varchar(1024) not null default '' after relayed_by,
+    add column use_gateway tinyint(1)    not null default 0 after routes;
@@ -0,0 +1,2 @@
+alter table wukuard
+    add column public_endpoint varchar(255) not null default '' after endpoint;
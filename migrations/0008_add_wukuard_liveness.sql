@@ -0,0 +1,4 @@
+alter table wukuard
+    add column last_seen      bigint       not null default 0 after use_gateway,
+    add column client_version varchar(64)  not null default '' after last_seen,
+    add column status         varchar(255) not null default '' after client_version;
@@ -0,0 +1,11 @@
+create table if not exists wukuard_peer_stats
+(
+    peer_id        int          not null,
+    public_key     varchar(64)  not null,
+    endpoint       varchar(255) not null default '',
+    last_handshake bigint       not null default 0,
+    rx_bytes       bigint       not null default 0,
+    tx_bytes       bigint       not null default 0,
+    reported_at    bigint       not null default 0,
+    primary key (peer_id, public_key)
+);
@@ -0,0 +1,7 @@
+create table if not exists wukuard_deleted_peer
+(
+    token_hash char(64)     not null primary key,
+    network    varchar(255) not null default '',
+    hostname   varchar(255) not null default '',
+    deleted_at bigint       not null
+);
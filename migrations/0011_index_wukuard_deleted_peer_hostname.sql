@@ -0,0 +1,5 @@
+alter table wukuard_deleted_peer
+    drop primary key,
+    add column id int not null auto_increment primary key first,
+    add index token_hash (token_hash),
+    add index network_hostname (network, hostname);
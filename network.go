@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NetworkConfig seeds the default network on the first start of the server,
+// which is then managed through the admin API like the other networks
+type NetworkConfig struct {
+	// CIDR is the IPv4 network of the overlay, e.g. 10.0.0.0/24
+	CIDR string `yaml:"cidr"`
+	// CIDR6 is the IPv6 network of the overlay, e.g. fd00::/64
+	CIDR6 string `yaml:"cidr6"`
+	// DNS is the comma separated DNS servers of the interfaces
+	DNS string `yaml:"dns"`
+	// ListenPort and PersistentKeepalive are the defaults of the new peers
+	ListenPort          int32 `yaml:"listenPort"`
+	PersistentKeepalive int32 `yaml:"persistentKeepalive"`
+}
+
+var networkNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+func validateNetwork(record *NetworkRecord) error {
+	if !networkNamePattern.MatchString(record.Name) {
+		return fmt.Errorf("name: %q must be lower case letters, digits, - and _", record.Name)
+	}
+	if _, err := parseNetworks(record); err != nil {
+		return fmt.Errorf("cidr: %w", err)
+	}
+	if record.CIDR != "" {
+		if ip, _, _ := net.ParseCIDR(record.CIDR); ip.To4() == nil {
+			return fmt.Errorf("cidr: %s is not IPv4", record.CIDR)
+		}
+	}
+	if record.CIDR6 != "" {
+		if ip, _, _ := net.ParseCIDR(record.CIDR6); ip.To4() != nil {
+			return fmt.Errorf("cidr6: %s is not IPv6", record.CIDR6)
+		}
+	}
+	for _, dns := range splitList(record.DNS) {
+		if net.ParseIP(dns) == nil {
+			return fmt.Errorf("dns: invalid IP %s", dns)
+		}
+	}
+	if record.ListenPort < 0 || record.ListenPort > 65535 {
+		return fmt.Errorf("listenPort: out of range: %d", record.ListenPort)
+	}
+	if record.PersistentKeepalive < 0 || record.PersistentKeepalive > 65535 {
+		return fmt.Errorf("persistentKeepalive: out of range: %d", record.PersistentKeepalive)
+	}
+	return nil
+}
+
+// ensureDefaultNetwork creates the default network from conf unless it exists
+func ensureDefaultNetwork(store PeerStore, conf NetworkConfig) error {
+	record := &NetworkRecord{
+		Name:                defaultNetworkName,
+		CIDR:                conf.CIDR,
+		CIDR6:               conf.CIDR6,
+		DNS:                 conf.DNS,
+		ListenPort:          conf.ListenPort,
+		PersistentKeepalive: conf.PersistentKeepalive,
+	}
+	if err := validateNetwork(record); err != nil {
+		return err
+	}
+	current, err := store.GetNetwork(defaultNetworkName)
+	if err == nil {
+		if current.CIDR != record.CIDR || current.CIDR6 != record.CIDR6 {
+			logger.warn("the default network differs from the config, update it through the admin API",
+				"network", current.Name, "cidr", current.CIDR, "cidr6", current.CIDR6)
+		}
+		return nil
+	}
+	if !errors.Is(err, errNotFound) {
+		return err
+	}
+	record.CreatedAt = time.Now().Unix()
+	record.UpdatedAt = record.CreatedAt
+	if err = store.CreateNetwork(record); err != nil {
+		return err
+	}
+	logger.info("created the default network", "network", record.Name, "cidr", record.CIDR, "cidr6", record.CIDR6)
+	return nil
+}
+
+func networkToPb(record *NetworkRecord, peerCount int) *pb.Network {
+	return &pb.Network{
+		Name:                record.Name,
+		Cidr:                record.CIDR,
+		Cidr6:               record.CIDR6,
+		Dns:                 record.DNS,
+		ListenPort:          record.ListenPort,
+		PersistentKeepalive: record.PersistentKeepalive,
+		PeerCount:           int32(peerCount),
+		CreatedAt:           record.CreatedAt,
+		UpdatedAt:           record.UpdatedAt,
+	}
+}
+
+func networkFromPb(network *pb.Network) *NetworkRecord {
+	return &NetworkRecord{
+		Name:                strings.TrimSpace(network.Name),
+		CIDR:                strings.TrimSpace(network.Cidr),
+		CIDR6:               strings.TrimSpace(network.Cidr6),
+		DNS:                 strings.TrimSpace(network.Dns),
+		ListenPort:          network.ListenPort,
+		PersistentKeepalive: network.PersistentKeepalive,
+	}
+}
+
+// networkError maps the errors of the store about the network named name to gRPC status
+func networkError(name string, err error) error {
+	if errors.Is(err, errNotFound) {
+		return status.Errorf(codes.NotFound, "network %s not found", name)
+	}
+	return ipamStatus(err)
+}
+
+func (s *adminServer) networkToPb(record *NetworkRecord) (*pb.Network, error) {
+	peers, err := s.store.ListPeers(record.Name)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return networkToPb(record, len(peers)), nil
+}
+
+func (s *adminServer) GetNetwork(_ context.Context, req *pb.GetNetworkRequest) (*pb.Network, error) {
+	name := req.Name
+	if name == "" {
+		name = defaultNetworkName
+	}
+	record, err := s.store.GetNetwork(name)
+	if err != nil {
+		return nil, networkError(name, err)
+	}
+	return s.networkToPb(record)
+}
+
+func (s *adminServer) ListNetworks(_ context.Context, _ *pb.ListNetworksRequest) (*pb.ListNetworksResponse, error) {
+	records, err := s.store.ListNetworks()
+	if err != nil {
+		return nil, storeError(err)
+	}
+	resp := &pb.ListNetworksResponse{Networks: make([]*pb.Network, 0, len(records))}
+	for _, record := range records {
+		network, err := s.networkToPb(record)
+		if err != nil {
+			return nil, err
+		}
+		resp.Networks = append(resp.Networks, network)
+	}
+	return resp, nil
+}
+
+func (s *adminServer) CreateNetwork(ctx context.Context, req *pb.CreateNetworkRequest) (*pb.Network, error) {
+	if req.Network == nil {
+		return nil, status.Error(codes.InvalidArgument, "network is required")
+	}
+	record := networkFromPb(req.Network)
+	if err := validateNetwork(record); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := s.store.GetNetwork(record.Name); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "network %s already exists", record.Name)
+	} else if !errors.Is(err, errNotFound) {
+		return nil, storeError(err)
+	}
+	record.CreatedAt = time.Now().Unix()
+	record.UpdatedAt = record.CreatedAt
+	if err := s.store.CreateNetwork(record); err != nil {
+		return nil, storeError(err)
+	}
+	loggerFrom(ctx).info("created network", "network", record.Name)
+	return networkToPb(record, 0), nil
+}
+
+func (s *adminServer) UpdateNetwork(ctx context.Context, req *pb.UpdateNetworkRequest) (*pb.Network, error) {
+	if req.Network == nil {
+		return nil, status.Error(codes.InvalidArgument, "network is required")
+	}
+	record := networkFromPb(req.Network)
+	if err := validateNetwork(record); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	record.UpdatedAt = time.Now().Unix()
+	if err := s.ipam.updateNetwork(s.store, record); err != nil {
+		return nil, networkError(record.Name, err)
+	}
+	loggerFrom(ctx).info("updated network", "network", record.Name)
+	return s.GetNetwork(ctx, &pb.GetNetworkRequest{Name: record.Name})
+}
+
+func (s *adminServer) DeleteNetwork(ctx context.Context, req *pb.DeleteNetworkRequest) (*pb.DeleteNetworkResponse, error) {
+	if req.Name == "" || req.Name == defaultNetworkName {
+		return nil, status.Error(codes.FailedPrecondition, "the default network can't be deleted")
+	}
+	if err := s.ipam.deleteNetwork(s.store, req.Name); err != nil {
+		return nil, networkError(req.Name, err)
+	}
+	loggerFrom(ctx).info("deleted network", "network", req.Name)
+	return &pb.DeleteNetworkResponse{}, nil
+}
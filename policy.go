@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// anyTag matches every peer in the From and To of policies
+const anyTag = "*"
+
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// validateTags checks a comma separated list of tags, which may contain anyTag if wildcard
+func validateTags(tags string, wildcard bool) error {
+	for _, tag := range splitList(tags) {
+		if wildcard && tag == anyTag {
+			continue
+		}
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// hasTag reports whether record is tagged with one of tags
+func hasTag(record *PeerRecord, tags []string) bool {
+	peerTags := splitList(record.Tags)
+	for _, tag := range tags {
+		if tag == anyTag {
+			return true
+		}
+		for _, peerTag := range peerTags {
+			if peerTag == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func maskedNet(ipNet *net.IPNet) *net.IPNet {
+	return &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask}
+}
+
+// restrictCIDRs returns the parts of allowedIPs within cidrs
+func restrictCIDRs(allowedIPs, cidrs []*net.IPNet) []*net.IPNet {
+	var restricted []*net.IPNet
+	for _, allowed := range allowedIPs {
+		for _, cidr := range cidrs {
+			if !overlaps(allowed, cidr) {
+				continue
+			}
+			allowedOnes, _ := allowed.Mask.Size()
+			cidrOnes, _ := cidr.Mask.Size()
+			if allowedOnes >= cidrOnes {
+				restricted = append(restricted, maskedNet(allowed))
+			} else {
+				restricted = append(restricted, maskedNet(cidr))
+			}
+		}
+	}
+	return restricted
+}
+
+// mergeCIDRs formats nets as AllowedIPs, without the CIDRs contained in others
+func mergeCIDRs(nets []*net.IPNet) string {
+	var merged []string
+	seen := make(map[string]bool)
+next:
+	for i, ipNet := range nets {
+		ones, _ := ipNet.Mask.Size()
+		for j, other := range nets {
+			otherOnes, _ := other.Mask.Size()
+			if i != j && other.Contains(ipNet.IP) && (otherOnes < ones || (otherOnes == ones && j < i)) {
+				continue next
+			}
+		}
+		cidr := maskedNet(ipNet).String()
+		if !seen[cidr] {
+			seen[cidr] = true
+			merged = append(merged, cidr)
+		}
+	}
+	return strings.Join(merged, ", ")
+}
+
+// policyAllowedIPs returns the AllowedIPs and routes of peer as seen by self under policies,
+// or an empty string if no policy lets them talk to each other.
+// WireGuard can't tell who opened a connection, so a peer allowed to reach self is also reachable by self,
+// which is needed for the answers anyway.
+func policyAllowedIPs(policies []*PolicyRecord, self, peer *PeerRecord) string {
+	peerAllowedIPs, err := parseCIDRList(advertisedIPs(self, peer))
+	if err != nil {
+		return ""
+	}
+	var allowed []*net.IPNet
+	for _, policy := range policies {
+		from, to := splitList(policy.From), splitList(policy.To)
+		if hasTag(self, from) && hasTag(peer, to) {
+			if policy.CIDRs == "" {
+				allowed = append(allowed, peerAllowedIPs...)
+			} else {
+				cidrs, _ := parseCIDRList(policy.CIDRs)
+				allowed = append(allowed, restrictCIDRs(peerAllowedIPs, cidrs)...)
+			}
+		}
+		if hasTag(peer, from) && hasTag(self, to) {
+			allowed = append(allowed, peerAllowedIPs...)
+		}
+	}
+	return mergeCIDRs(allowed)
+}
+
+func policyToPb(record *PolicyRecord) *pb.Policy {
+	return &pb.Policy{
+		Id:          record.ID,
+		Network:     record.Network,
+		Description: record.Description,
+		From:        record.From,
+		To:          record.To,
+		Cidrs:       record.CIDRs,
+		CreatedAt:   record.CreatedAt,
+		UpdatedAt:   record.UpdatedAt,
+	}
+}
+
+func policyFromPb(policy *pb.Policy) *PolicyRecord {
+	return &PolicyRecord{
+		ID:          policy.Id,
+		Network:     strings.TrimSpace(policy.Network),
+		Description: strings.TrimSpace(policy.Description),
+		From:        strings.TrimSpace(policy.From),
+		To:          strings.TrimSpace(policy.To),
+		CIDRs:       strings.TrimSpace(policy.Cidrs),
+	}
+}
+
+func validatePolicy(record *PolicyRecord) error {
+	if len(splitList(record.From)) <= 0 {
+		return errors.New("from is required")
+	}
+	if err := validateTags(record.From, true); err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	if len(splitList(record.To)) <= 0 {
+		return errors.New("to is required")
+	}
+	if err := validateTags(record.To, true); err != nil {
+		return fmt.Errorf("to: %w", err)
+	}
+	if record.CIDRs != "" {
+		if err := validateCIDRList(record.CIDRs); err != nil {
+			return fmt.Errorf("cidrs: %w", err)
+		}
+	}
+	return nil
+}
+
+// policyError maps the errors of the store about a policy to gRPC status
+func policyError(err error) error {
+	if errors.Is(err, errNotFound) {
+		return status.Error(codes.NotFound, "policy not found")
+	}
+	return storeError(err)
+}
+
+// checkPolicy validates record and makes sure that its network exists
+func (s *adminServer) checkPolicy(record *PolicyRecord) error {
+	if record.Network == "" {
+		record.Network = defaultNetworkName
+	}
+	if err := validatePolicy(record); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := s.store.GetNetwork(record.Network); err != nil {
+		if errors.Is(err, errNotFound) {
+			return status.Errorf(codes.InvalidArgument, "unknown network %s", record.Network)
+		}
+		return storeError(err)
+	}
+	return nil
+}
+
+func (s *adminServer) CreatePolicy(ctx context.Context, req *pb.CreatePolicyRequest) (*pb.Policy, error) {
+	if req.Policy == nil {
+		return nil, status.Error(codes.InvalidArgument, "policy is required")
+	}
+	record := policyFromPb(req.Policy)
+	if err := s.checkPolicy(record); err != nil {
+		return nil, err
+	}
+	record.CreatedAt = time.Now().Unix()
+	record.UpdatedAt = record.CreatedAt
+	if err := s.store.CreatePolicy(record); err != nil {
+		return nil, storeError(err)
+	}
+	loggerFrom(ctx).info("created policy", "policy", record.ID, "network", record.Network, "from", record.From, "to", record.To)
+	return policyToPb(record), nil
+}
+
+func (s *adminServer) UpdatePolicy(ctx context.Context, req *pb.UpdatePolicyRequest) (*pb.Policy, error) {
+	if req.Policy == nil {
+		return nil, status.Error(codes.InvalidArgument, "policy is required")
+	}
+	record := policyFromPb(req.Policy)
+	if err := s.checkPolicy(record); err != nil {
+		return nil, err
+	}
+	record.UpdatedAt = time.Now().Unix()
+	if err := s.store.UpdatePolicy(record); err != nil {
+		return nil, policyError(err)
+	}
+	loggerFrom(ctx).info("updated policy", "policy", record.ID, "network", record.Network, "from", record.From, "to", record.To)
+	return s.GetPolicy(ctx, &pb.GetPolicyRequest{Id: record.ID})
+}
+
+func (s *adminServer) DeletePolicy(ctx context.Context, req *pb.DeletePolicyRequest) (*pb.DeletePolicyResponse, error) {
+	if err := s.store.DeletePolicy(req.Id); err != nil {
+		return nil, policyError(err)
+	}
+	loggerFrom(ctx).info("deleted policy", "policy", req.Id)
+	return &pb.DeletePolicyResponse{}, nil
+}
+
+func (s *adminServer) ListPolicies(_ context.Context, req *pb.ListPoliciesRequest) (*pb.ListPoliciesResponse, error) {
+	records, err := s.store.ListPolicies(req.Network)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	resp := &pb.ListPoliciesResponse{Policies: make([]*pb.Policy, 0, len(records))}
+	for _, record := range records {
+		resp.Policies = append(resp.Policies, policyToPb(record))
+	}
+	return resp, nil
+}
+
+func (s *adminServer) GetPolicy(_ context.Context, req *pb.GetPolicyRequest) (*pb.Policy, error) {
+	record, err := s.store.GetPolicy(req.Id)
+	if err != nil {
+		return nil, policyError(err)
+	}
+	return policyToPb(record), nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	pb "github.com/loheagn/wukuard/grpc"
+)
+
+// policyPeers are the peers of the policy tests, by hostname
+func policyPeers() map[string]*PeerRecord {
+	peers := make(map[string]*PeerRecord)
+	for i, record := range []*PeerRecord{
+		{Hostname: "web", Tags: "web", AllowedIPs: "10.0.0.1/32"},
+		{Hostname: "db", Tags: "db, backup", AllowedIPs: "10.0.0.2/32", Routes: "192.168.2.0/24"},
+		{Hostname: "ops", Tags: "ops", AllowedIPs: "10.0.0.3/32"},
+		{Hostname: "lone", AllowedIPs: "10.0.0.4/32"},
+		{Hostname: "relay", Relay: true, AllowedIPs: "10.0.0.5/32"},
+	} {
+		record.ID = int32(i + 1)
+		record.PublicKey = "key-" + record.Hostname
+		peers[record.Hostname] = record
+	}
+	return peers
+}
+
+func TestPolicyAllowedIPs(t *testing.T) {
+	peers := policyPeers()
+	webToDB := &PolicyRecord{From: "web", To: "db"}
+	for _, test := range []struct {
+		name       string
+		policies   []*PolicyRecord
+		self, peer string
+		want       string
+	}{
+		{"from reaches to", []*PolicyRecord{webToDB}, "web", "db", "10.0.0.2/32, 192.168.2.0/24"},
+		{"to sees from to answer", []*PolicyRecord{webToDB}, "db", "web", "10.0.0.1/32"},
+		{"other tags", []*PolicyRecord{webToDB}, "web", "ops", ""},
+		{"any of the tags", []*PolicyRecord{{From: "web", To: "cache, backup"}}, "web", "db", "10.0.0.2/32, 192.168.2.0/24"},
+		{"wildcard to", []*PolicyRecord{{From: "ops", To: anyTag}}, "ops", "lone", "10.0.0.4/32"},
+		{"wildcard from", []*PolicyRecord{{From: anyTag, To: "ops"}}, "lone", "ops", "10.0.0.3/32"},
+		{"wildcards don't pair the others", []*PolicyRecord{{From: "ops", To: anyTag}}, "web", "db", ""},
+		{"restricted to cidrs", []*PolicyRecord{{From: "web", To: "db", CIDRs: "192.168.2.128/25"}}, "web", "db", "192.168.2.128/25"},
+		{"cidrs out of the peer", []*PolicyRecord{{From: "web", To: "db", CIDRs: "172.16.0.0/12"}}, "web", "db", ""},
+		{"cidrs don't restrict the answers", []*PolicyRecord{{From: "web", To: "db", CIDRs: "192.168.2.0/24"}}, "db", "web", "10.0.0.1/32"},
+		{"merged policies", []*PolicyRecord{
+			{From: "web", To: "db", CIDRs: "192.168.2.0/25"},
+			{From: "web", To: "backup", CIDRs: "192.168.2.0/24"},
+		}, "web", "db", "192.168.2.0/24"},
+		{"untagged peer isolated", []*PolicyRecord{webToDB}, "lone", "web", ""},
+	} {
+		if got := policyAllowedIPs(test.policies, peers[test.self], peers[test.peer]); got != test.want {
+			t.Errorf("%s: %s sees %s on %q, want %q", test.name, test.self, test.peer, got, test.want)
+		}
+	}
+}
+
+// peerAllowedIPs returns the AllowedIPs of peerList by public key
+func peerAllowedIPs(peerList []*pb.PeerResponse) map[string]string {
+	allowedIPs := make(map[string]string)
+	for _, peer := range peerList {
+		allowedIPs[peer.PublicKey] = peer.AllowedIPs
+	}
+	return allowedIPs
+}
+
+func TestBuildPeerListPolicies(t *testing.T) {
+	peers := policyPeers()
+	records := []*PeerRecord{peers["web"], peers["db"], peers["ops"], peers["lone"], peers["relay"]}
+	policies := []*PolicyRecord{{From: "web", To: "db"}}
+
+	// without policy, the network is a full mesh
+	if got := buildPeerList(peers["lone"], records, nil); len(got) != 4 {
+		t.Errorf("peers of lone without policy = %v", peerAllowedIPs(got))
+	}
+	// with policies, the peers no policy pairs see nobody
+	if got := buildPeerList(peers["lone"], records, policies); len(got) != 0 {
+		t.Errorf("peers of lone = %v", peerAllowedIPs(got))
+	}
+	got := peerAllowedIPs(buildPeerList(peers["web"], records, policies))
+	if len(got) != 1 || got["key-db"] != "10.0.0.2/32, 192.168.2.0/24" {
+		t.Errorf("peers of web = %v", got)
+	}
+	// a relay sees every peer whatever the policies
+	if got := buildPeerList(peers["relay"], records, policies); len(got) != 4 {
+		t.Errorf("peers of the relay = %v", peerAllowedIPs(got))
+	}
+}
+
+func TestBuildPeerListRelayedPolicies(t *testing.T) {
+	peers := policyPeers()
+	peers["db"].RelayedBy = peers["relay"].ID
+	records := []*PeerRecord{peers["web"], peers["db"], peers["ops"], peers["lone"], peers["relay"]}
+	policies := []*PolicyRecord{{From: "web", To: "db"}, {From: "ops", To: "web"}}
+
+	// the relayed db is reached through the relay, on what the policies allow only
+	got := peerAllowedIPs(buildPeerList(peers["web"], records, policies))
+	if len(got) != 2 || got["key-relay"] != "10.0.0.2/32, 192.168.2.0/24" || got["key-ops"] != "10.0.0.3/32" {
+		t.Errorf("peers of web = %v", got)
+	}
+	// the peers not allowed to reach db don't route it through the relay
+	if got := peerAllowedIPs(buildPeerList(peers["ops"], records, policies)); len(got) != 1 || got["key-web"] != "10.0.0.1/32" {
+		t.Errorf("peers of ops = %v", got)
+	}
+	// db reaches the peers it may talk to through its relay alone
+	got = peerAllowedIPs(buildPeerList(peers["db"], records, policies))
+	if len(got) != 1 || got["key-relay"] != "10.0.0.1/32" {
+		t.Errorf("peers of db = %v", got)
+	}
+	// the relay forwards for db whatever the policies
+	got = peerAllowedIPs(buildPeerList(peers["relay"], records, policies))
+	if got["key-db"] != "10.0.0.2/32, 192.168.2.0/24" || len(got) != 4 {
+		t.Errorf("peers of the relay = %v", got)
+	}
+}
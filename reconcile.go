@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// peerChange is an existing peer whose configuration changed
+type peerChange struct {
+	peer    *PeerConf
+	changes []string
+}
+
+// Plan is the minimal set of operations bringing the interface from its actual state to desired
+type Plan struct {
+	desired *WgConf
+
+	createInterface  bool
+	interfaceChanges []string
+	addPeers         []*PeerConf
+	updatePeers      []*peerChange
+	removePeers      []*PeerConf
+}
+
+func (p *Plan) empty() bool {
+	return !p.createInterface && len(p.interfaceChanges) <= 0 &&
+		len(p.addPeers) <= 0 && len(p.updatePeers) <= 0 && len(p.removePeers) <= 0
+}
+
+// interfaceChanged reports whether field of the interface changed
+func (p *Plan) interfaceChanged(field string) bool {
+	for _, v := range p.interfaceChanges {
+		if v == field {
+			return true
+		}
+	}
+	return p.createInterface
+}
+
+func (p *Plan) String() string {
+	var lines []string
+	if p.createInterface {
+		lines = append(lines, "create interface")
+	}
+	if len(p.interfaceChanges) > 0 {
+		lines = append(lines, "update interface: "+strings.Join(p.interfaceChanges, ", "))
+	}
+	for _, peer := range p.addPeers {
+		lines = append(lines, fmt.Sprintf("add peer %s (%s)", peer.PublicKey, peer.AllowedIPs))
+	}
+	for _, change := range p.updatePeers {
+		lines = append(lines, fmt.Sprintf("update peer %s: %s", change.peer.PublicKey, strings.Join(change.changes, ", ")))
+	}
+	for _, peer := range p.removePeers {
+		lines = append(lines, fmt.Sprintf("remove peer %s (%s)", peer.PublicKey, peer.AllowedIPs))
+	}
+	return strings.Join(lines, "; ")
+}
+
+// normalizeCIDRList makes comma separated CIDRs comparable, ignoring order and spaces
+func normalizeCIDRList(value string) string {
+	return joinCIDRs(value, false)
+}
+
+// normalizeAllowedIPs is normalizeCIDRList with the host bits cleared, as the device reports masked allowed IPs
+func normalizeAllowedIPs(value string) string {
+	return joinCIDRs(value, true)
+}
+
+func joinCIDRs(value string, mask bool) string {
+	nets, err := parseCIDRList(value)
+	if err != nil {
+		return value
+	}
+	var items []string
+	for _, network := range nets {
+		if mask {
+			network = maskedNet(network)
+		}
+		items = append(items, network.String())
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// normalizeEndpoint resolves the host of endpoint, as the device only knows about IPs
+func normalizeEndpoint(endpoint string) string {
+	if endpoint == "" {
+		return ""
+	}
+	addr, err := net.ResolveUDPAddr("udp", endpoint)
+	if err != nil {
+		return endpoint
+	}
+	return addr.String()
+}
+
+// normalizeDNS makes DNS lists comparable whatever their spacing
+func normalizeDNS(dns string) string {
+	return strings.Join(splitList(dns), ",")
+}
+
+func diffInterface(desired, actual *InterfaceConf) []string {
+	var changes []string
+	if desired.PrivateKey != actual.PrivateKey {
+		changes = append(changes, "private key")
+	}
+	if desired.ListenPort != actual.ListenPort {
+		changes = append(changes, "listen port")
+	}
+	if normalizeCIDRList(desired.Address) != normalizeCIDRList(actual.Address) {
+		changes = append(changes, "address")
+	}
+	if desired.PostUp != actual.PostUp {
+		changes = append(changes, "PostUp")
+	}
+	if desired.PreDown != actual.PreDown {
+		changes = append(changes, "PreDown")
+	}
+	if normalizeDNS(desired.DNS) != normalizeDNS(actual.DNS) {
+		changes = append(changes, "DNS")
+	}
+	return changes
+}
+
+func diffPeer(desired, actual *PeerConf) []string {
+	var changes []string
+	if normalizeEndpoint(desired.Endpoint) != normalizeEndpoint(actual.Endpoint) {
+		changes = append(changes, "endpoint")
+	}
+	if normalizeAllowedIPs(desired.AllowedIPs) != normalizeAllowedIPs(actual.AllowedIPs) {
+		changes = append(changes, "AllowedIPs")
+	}
+	if desired.PersistentKeepalive != actual.PersistentKeepalive {
+		changes = append(changes, "PersistentKeepalive")
+	}
+	return changes
+}
+
+// diffWgConf computes the plan to go from actual, nil if the interface doesn't exist, to desired
+func diffWgConf(desired, actual *WgConf) *Plan {
+	plan := &Plan{desired: desired}
+	if actual == nil || actual.interfaceConf == nil {
+		plan.createInterface = true
+		plan.addPeers = desired.peerConfList
+		return plan
+	}
+	plan.interfaceChanges = diffInterface(desired.interfaceConf, actual.interfaceConf)
+
+	actualPeers := make(map[string]*PeerConf)
+	for _, peer := range actual.peerConfList {
+		actualPeers[peer.PublicKey] = peer
+	}
+	for _, peer := range desired.peerConfList {
+		actualPeer, ok := actualPeers[peer.PublicKey]
+		if !ok {
+			plan.addPeers = append(plan.addPeers, peer)
+			continue
+		}
+		delete(actualPeers, peer.PublicKey)
+		if changes := diffPeer(peer, actualPeer); len(changes) > 0 {
+			plan.updatePeers = append(plan.updatePeers, &peerChange{peer: peer, changes: changes})
+		}
+	}
+	for _, peer := range actual.peerConfList {
+		if _, ok := actualPeers[peer.PublicKey]; ok {
+			plan.removePeers = append(plan.removePeers, peer)
+		}
+	}
+	return plan
+}
+
+// reconcile applies the operations needed to bring the interface to desired, if any,
+// and tells whether there were any
+func reconcile(backend WireGuardBackend, desired *WgConf, log *fieldLogger) (bool, error) {
+	actual, err := backend.Current()
+	if err != nil {
+		return false, fmt.Errorf("read the interface: %w", err)
+	}
+	plan := diffWgConf(desired, actual)
+	if plan.empty() {
+		return false, nil
+	}
+	log.info("apply plan", "plan", plan)
+	return true, backend.Apply(plan)
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestDiffWgConf(t *testing.T) {
+	iface := &InterfaceConf{PrivateKey: "key", Address: "10.0.0.5/24", ListenPort: 9619}
+	desired := &WgConf{interfaceConf: iface, peerConfList: []*PeerConf{
+		{PublicKey: "a", AllowedIPs: "10.1.0.5/24, 10.0.0.1/32", Endpoint: "192.0.2.1:9619"},
+		{PublicKey: "b", AllowedIPs: "10.0.0.2/32"},
+	}}
+
+	if plan := diffWgConf(desired, nil); !plan.createInterface || len(plan.addPeers) != 2 {
+		t.Errorf("plan without interface = %s", plan)
+	}
+
+	// the device reports masked allowed IPs, in its own order
+	actual := &WgConf{interfaceConf: &InterfaceConf{PrivateKey: "key", Address: "10.0.0.5/24", ListenPort: 9619}, peerConfList: []*PeerConf{
+		{PublicKey: "a", AllowedIPs: "10.0.0.1/32,10.1.0.0/24", Endpoint: "192.0.2.1:9619"},
+		{PublicKey: "b", AllowedIPs: "10.0.0.2/32"},
+	}}
+	if plan := diffWgConf(desired, actual); !plan.empty() {
+		t.Errorf("plan of an interface in sync = %s", plan)
+	}
+
+	actual.interfaceConf.Address = "10.0.0.6/24"
+	actual.peerConfList = []*PeerConf{
+		{PublicKey: "a", AllowedIPs: "10.1.0.0/16, 10.0.0.1/32", Endpoint: "192.0.2.1:9619"},
+		{PublicKey: "c", AllowedIPs: "10.0.0.3/32"},
+	}
+	plan := diffWgConf(desired, actual)
+	if !plan.interfaceChanged("address") {
+		t.Errorf("address change missed: %s", plan)
+	}
+	if len(plan.updatePeers) != 1 || plan.updatePeers[0].peer.PublicKey != "a" ||
+		len(plan.addPeers) != 1 || plan.addPeers[0].PublicKey != "b" ||
+		len(plan.removePeers) != 1 || plan.removePeers[0].PublicKey != "c" {
+		t.Errorf("plan = %s", plan)
+	}
+}
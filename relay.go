@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// relayKeepalive keeps the NAT mapping of a relayed peer to its relay open, unless the relay sets its own
+const relayKeepalive = 25
+
+// forwardingFiles are the sysctls relays and gateways turn on to forward the traffic of other peers
+var forwardingFiles = []string{
+	"/proc/sys/net/ipv4/ip_forward",
+	"/proc/sys/net/ipv6/conf/all/forwarding",
+}
+
+// relayRoute is a peer of self and the AllowedIPs self routes through it
+type relayRoute struct {
+	record *PeerRecord
+	nets   []*net.IPNet
+}
+
+// buildPeerList returns the peers of self among records.
+// Every peer is reached directly, but the peers relayed by another peer which are reached through their relay,
+// and a relayed self reaches every peer through its own relay: the relay gets the AllowedIPs of all the peers behind it.
+// A relay sees every peer of the network whatever the policies, so that it can forward their traffic.
+func buildPeerList(self *PeerRecord, records []*PeerRecord, policies []*PolicyRecord) []*pb.PeerResponse {
+	byID := make(map[int32]*PeerRecord, len(records))
+	for _, record := range records {
+		byID[record.ID] = record
+	}
+	// relayOf returns the relay record is reached through, nil if it is reached directly
+	relayOf := func(record *PeerRecord) *PeerRecord {
+		relay := byID[record.RelayedBy]
+		if relay == nil || !relay.Relay || relay.ID == record.ID || relay.RelayedBy != 0 {
+			return nil
+		}
+		return relay
+	}
+	selfRelay := relayOf(self)
+
+	var routes []*relayRoute
+	routeOf := make(map[int32]*relayRoute)
+	for _, v := range records {
+		if v.ID == self.ID {
+			continue
+		}
+		allowedIPs := advertisedIPs(self, v)
+		if len(policies) > 0 && !self.Relay {
+			allowedIPs = policyAllowedIPs(policies, self, v)
+		}
+		nets, err := parseCIDRList(allowedIPs)
+		if err != nil || len(nets) <= 0 {
+			continue
+		}
+		via := v
+		if selfRelay != nil {
+			via = selfRelay
+		} else if relay := relayOf(v); relay != nil && relay.ID != self.ID {
+			via = relay
+		}
+		route := routeOf[via.ID]
+		if route == nil {
+			route = &relayRoute{record: via}
+			routeOf[via.ID] = route
+			routes = append(routes, route)
+		}
+		route.nets = append(route.nets, nets...)
+	}
+
+	peerList := make([]*pb.PeerResponse, 0, len(routes))
+	for _, route := range routes {
+		keepalive := route.record.PersistentKeepalive
+		if route.record == selfRelay && keepalive == 0 {
+			keepalive = relayKeepalive
+		}
+		peerList = append(peerList, &pb.PeerResponse{
+			Id:                  route.record.ID,
+			Endpoint:            peerEndpoint(self, route.record),
+			PublicKey:           route.record.PublicKey,
+			AllowedIPs:          mergeCIDRs(route.nets),
+			PersistentKeepalive: keepalive,
+		})
+	}
+	return peerList
+}
+
+// checkRelay makes sure that the relay of record, if any, is a relay of its network,
+// and that record is still a relay if other peers are relayed by it
+func (s *adminServer) checkRelay(record *PeerRecord) error {
+	if record.RelayedBy != 0 {
+		if record.RelayedBy == record.ID {
+			return status.Error(codes.InvalidArgument, "a peer can't be relayed by itself")
+		}
+		if record.Relay {
+			return status.Error(codes.InvalidArgument, "a relay can't be relayed")
+		}
+		relay, err := s.store.GetPeer(record.RelayedBy)
+		if err != nil {
+			if errors.Is(err, errNotFound) {
+				return status.Errorf(codes.InvalidArgument, "unknown relay %d", record.RelayedBy)
+			}
+			return storeError(err)
+		}
+		if relay.Network != record.Network {
+			return status.Errorf(codes.InvalidArgument, "relay %d is in network %s", relay.ID, relay.Network)
+		}
+		if !relay.Relay {
+			return status.Errorf(codes.InvalidArgument, "peer %d is not a relay", relay.ID)
+		}
+	}
+	if record.ID != 0 && !record.Relay {
+		return s.checkRelayed(record.ID)
+	}
+	return nil
+}
+
+// checkRelayed makes sure that no peer is relayed by id
+func (s *adminServer) checkRelayed(id int32) error {
+	peers, err := s.store.ListPeers("")
+	if err != nil {
+		return storeError(err)
+	}
+	var relayed []string
+	for _, peer := range peers {
+		if peer.RelayedBy == id {
+			relayed = append(relayed, fmt.Sprint(peer.ID))
+		}
+	}
+	if len(relayed) > 0 {
+		return status.Errorf(codes.FailedPrecondition, "peer %d still relays peers %s", id, strings.Join(relayed, ", "))
+	}
+	return nil
+}
+
+// enableForwarding turns on the IP forwarding of the host, which relays and gateways need.
+// It is never turned off again, since other services of the host may rely on it.
+func enableForwarding() error {
+	for _, filename := range forwardingFiles {
+		content, err := readFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if strings.TrimSpace(content) == "1" {
+			continue
+		}
+		if err = os.WriteFile(filename, []byte("1\n"), 0644); err != nil {
+			return fmt.Errorf("enable forwarding: %w", err)
+		}
+		logger.info("enabled forwarding to forward the traffic of other peers", "file", filename)
+	}
+	return nil
+}
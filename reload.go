@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// serverSettings are the settings of the server that a reload changes while it runs,
+// shared by its services
+type serverSettings struct {
+	mu    sync.RWMutex
+	live  LivenessConfig
+	token string
+}
+
+func (s *serverSettings) set(conf *ServerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live, s.token = conf.Liveness, conf.Admin.Token
+}
+
+func (s *serverSettings) liveness() LivenessConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.live
+}
+
+func (s *serverSettings) adminToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token
+}
+
+// serverReload applies the config file of a running server again on SIGHUP
+type serverReload struct {
+	confPath string
+	// started is the config the server was started with
+	started *ServerConfig
+	server  *server
+	// metricsAddr is where the metrics are served, if anywhere
+	metricsAddr   string
+	metricsServer *http.Server
+	serveMetrics  func(addr string) (*http.Server, error)
+}
+
+// reload applies the log, liveness, admin token and metrics settings of the config file,
+// and tells which of the other settings changed, as they take a restart
+func (r *serverReload) reload() {
+	conf, err := loadServerConfig(r.confPath)
+	if err == nil {
+		err = configureLogging(conf.Log)
+	}
+	if err != nil {
+		logger.error("reload the config, keep the current one", "file", r.confPath, "err", err)
+		return
+	}
+	if conf.MetricsAddr != r.metricsAddr {
+		r.moveMetrics(conf.MetricsAddr)
+	}
+	notify := conf.Liveness != r.server.settings.liveness()
+	r.server.settings.set(conf)
+	if notify {
+		// the offline peers may be left out of the networks, or back in
+		r.server.broadcaster.notify()
+	}
+	if changed := r.restartSettings(conf); len(changed) > 0 {
+		logger.warn("some settings take a restart, keep their current values", "settings", strings.Join(changed, ","))
+	}
+	logger.info("reloaded the config", "file", r.confPath)
+}
+
+// moveMetrics serves the metrics on addr rather than on the current address, or stops serving them if addr is empty
+func (r *serverReload) moveMetrics(addr string) {
+	var metricsServer *http.Server
+	if addr != "" {
+		var err error
+		// the new address is bound before the current one is released
+		if metricsServer, err = r.serveMetrics(addr); err != nil {
+			logger.error("serve the metrics on the new address, keep the current one", "addr", addr, "err", err)
+			return
+		}
+	}
+	if addr == "" {
+		logger.info("no metricsAddr, stop serving the metrics, /healthz and /readyz")
+	}
+	shutdownHTTP(r.metricsServer)
+	r.metricsAddr, r.metricsServer = addr, metricsServer
+}
+
+// restartSettings lists the settings of conf which differ from the ones the server was started with,
+// and take a restart to be applied
+func (r *serverReload) restartSettings(conf *ServerConfig) []string {
+	var changed []string
+	for _, setting := range []struct {
+		name    string
+		changed bool
+	}{
+		{"store", conf.Store != r.started.Store || conf.DB != r.started.DB},
+		{"port", conf.Port != r.started.Port},
+		{"tls", conf.TLS != r.started.TLS},
+		{"admin.httpPort", conf.Admin.HTTPPort != r.started.Admin.HTTPPort},
+	} {
+		if setting.changed {
+			changed = append(changed, setting.name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestServerReload(t *testing.T) {
+	n := newTestNetwork(t)
+	confPath := filepath.Join(t.TempDir(), "server.yaml")
+	writeConf := func(content string) {
+		if err := os.WriteFile(confPath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	started := &ServerConfig{Port: "9618", MetricsAddr: "127.0.0.1:9100"}
+	var served []string
+	r := &serverReload{confPath: confPath, started: started, server: n.server, metricsAddr: started.MetricsAddr}
+	r.serveMetrics = func(addr string) (*http.Server, error) {
+		if addr == "127.0.0.1:9300" {
+			return nil, errors.New("address already in use")
+		}
+		served = append(served, addr)
+		return &http.Server{Addr: addr}, nil
+	}
+
+	writeConf(`
+port: "9619"
+metricsAddr: 127.0.0.1:9200
+admin:
+  token: secret
+liveness:
+  offlineAfter: 1m
+  excludeOffline: true
+`)
+	r.reload()
+	if liveness := n.server.settings.liveness(); liveness.OfflineAfter != time.Minute || !liveness.ExcludeOffline {
+		t.Errorf("liveness = %+v", liveness)
+	}
+	if token := n.server.settings.adminToken(); token != "secret" {
+		t.Errorf("admin token = %q", token)
+	}
+	if r.metricsAddr != "127.0.0.1:9200" || !reflect.DeepEqual(served, []string{"127.0.0.1:9200"}) {
+		t.Errorf("metrics served on %s, listened on %v", r.metricsAddr, served)
+	}
+	if changed := r.restartSettings(&ServerConfig{Port: "9619"}); !reflect.DeepEqual(changed, []string{"port"}) {
+		t.Errorf("settings taking a restart = %v", changed)
+	}
+
+	// an invalid config changes nothing
+	writeConf("admin:\n  token: other\nlog:\n  level: loud\n")
+	r.reload()
+	if token := n.server.settings.adminToken(); token != "secret" {
+		t.Errorf("admin token of an invalid config applied: %q", token)
+	}
+
+	// the metrics stay where they are if the new address can't be bound
+	writeConf("metricsAddr: 127.0.0.1:9300\n")
+	r.reload()
+	if r.metricsAddr != "127.0.0.1:9200" || r.metricsServer == nil {
+		t.Errorf("metrics moved to %s", r.metricsAddr)
+	}
+	writeConf("admin:\n  token: secret\n")
+	r.reload()
+	if r.metricsAddr != "" || r.metricsServer != nil {
+		t.Errorf("metrics still served on %s", r.metricsAddr)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// advertisedIPs returns what self routes to peer: its AllowedIPs and the subnets it advertises as a gateway,
+// but its default routes unless self uses the gateway of the network
+func advertisedIPs(self, peer *PeerRecord) string {
+	allowedIPs := splitList(peer.AllowedIPs)
+	routes, err := parseCIDRList(peer.Routes)
+	if err != nil {
+		return strings.Join(allowedIPs, ", ")
+	}
+	for _, route := range routes {
+		if isDefaultRoute(route) && !self.UseGateway {
+			continue
+		}
+		allowedIPs = append(allowedIPs, maskedNet(route).String())
+	}
+	return strings.Join(allowedIPs, ", ")
+}
+
+// peerEndpoint picks the endpoint self reaches peer at:
+// its local endpoint from behind the same public address, or if it reported no public one, its public endpoint otherwise
+func peerEndpoint(self, peer *PeerRecord) string {
+	if peer.PublicEndpoint == "" {
+		return peer.Endpoint
+	}
+	if peer.Endpoint != "" && endpointHost(self.PublicEndpoint) == endpointHost(peer.PublicEndpoint) {
+		return peer.Endpoint
+	}
+	return peer.PublicEndpoint
+}
+
+func endpointHost(endpoint string) string {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// gatewayHooks returns the commands which let the peers of the interface with address out through the host,
+// masquerading them as the host. They can be run again without adding the rules twice.
+func gatewayHooks(address string) (postUp, preDown string) {
+	addresses, err := parseCIDRList(address)
+	if err != nil {
+		return "", ""
+	}
+	var up, down []string
+	for _, address := range addresses {
+		iptables := "iptables"
+		if address.IP.To4() == nil {
+			iptables = "ip6tables"
+		}
+		rules := []string{
+			"FORWARD -i %i -j ACCEPT",
+			"FORWARD -o %i -j ACCEPT",
+			fmt.Sprintf("POSTROUTING -t nat -s %s ! -o %%i -j MASQUERADE", maskedNet(address)),
+		}
+		for _, rule := range rules {
+			up = append(up, fmt.Sprintf("%s -C %s 2>/dev/null || %s -A %s", iptables, rule, iptables, rule))
+			down = append(down, fmt.Sprintf("%s -D %s", iptables, rule))
+		}
+	}
+	return strings.Join(up, "; "), strings.Join(down, "; ")
+}
+
+// joinHooks joins the non empty hooks into one
+func joinHooks(hooks ...string) string {
+	var joined []string
+	for _, hook := range hooks {
+		if hook = strings.TrimSpace(hook); hook != "" {
+			joined = append(joined, hook)
+		}
+	}
+	return strings.Join(joined, "; ")
+}
+
+// defaultRouteFamilies returns the families, "-4" or "-6", of the default routes in nets
+func defaultRouteFamilies(nets []*net.IPNet) map[string]bool {
+	families := make(map[string]bool)
+	for _, ipNet := range nets {
+		if !isDefaultRoute(ipNet) {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			families["-4"] = true
+		} else {
+			families["-6"] = true
+		}
+	}
+	return families
+}
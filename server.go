@@ -2,211 +2,468 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
 	pb "github.com/loheagn/wukuard/grpc"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"gopkg.in/yaml.v3"
 )
 
 type ServerConfig struct {
-	DB struct {
-		Host     string `yaml:"host"`
-		Name     string `yaml:"name"`
-		User     string `yaml:"user"`
-		Password string `yaml:"password"`
-	} `yaml:"db"`
-	Port string `yaml:"port"`
-}
-
-type PeerRecord struct {
-	id                  int32
-	macAddress          sql.NullString
-	hostname            string
-	token               sql.NullString
-	publicKey           string
-	privateKey          string
-	postUP              string
-	preDown             string
-	address             string
-	listenPort          int32
-	endPoint            string
-	allowedIPs          string
-	persistentKeepalive int32
-	createdAt           int64
-	updatedAt           int64
-}
-
-var db *sql.DB
+	Store   StoreConfig     `yaml:"store"`
+	DB      DBConfig        `yaml:"db"`
+	Port    string          `yaml:"port"`
+	TLS     ServerTLSConfig `yaml:"tls"`
+	Admin   AdminConfig     `yaml:"admin"`
+	Network NetworkConfig   `yaml:"network"`
+	// Liveness tells when peers are offline
+	Liveness LivenessConfig `yaml:"liveness"`
+	// MetricsAddr is the host:port to expose the metrics of the server on, if set
+	MetricsAddr string    `yaml:"metricsAddr"`
+	Log         LogConfig `yaml:"log"`
+}
 
 type server struct {
 	pb.UnsafeSyncNetServer
+	store       PeerStore
+	ipam        *ipam
+	broadcaster *broadcaster
+	settings    *serverSettings
+	// stopping is closed when the server shuts down, ending the network streams
+	stopping <-chan struct{}
+}
+
+func newServer(store PeerStore, ipam *ipam) *server {
+	b := newBroadcaster()
+	return &server{
+		store:       &notifyingStore{PeerStore: &countingStore{PeerStore: store}, broadcaster: b},
+		ipam:        ipam,
+		broadcaster: b,
+		settings:    &serverSettings{},
+	}
 }
 
-func readPeerRecord(rows *sql.Rows) *PeerRecord {
-	record := new(PeerRecord)
-	err := rows.Scan(
-		&(record.id),
-		&(record.macAddress),
-		&(record.hostname),
-		&(record.token),
-		&(record.publicKey),
-		&(record.privateKey),
-		&(record.postUP),
-		&(record.preDown),
-		&(record.address),
-		&(record.listenPort),
-		&(record.endPoint),
-		&(record.allowedIPs),
-		&(record.persistentKeepalive),
-		&(record.createdAt),
-		&(record.updatedAt),
-	)
-	if err != nil {
-		log.Printf("ERROR: read peerRecord from DB: %s\n", err.Error())
+// fetchRecordByHostname logs the errors of the store, returning nil if nothing is found
+func (s *server) fetchRecordByHostname(ctx context.Context, network, hostname string) *PeerRecord {
+	record, err := s.store.GetPeerByHostname(network, hostname)
+	if err != nil {
+		if !errors.Is(err, errNotFound) {
+			loggerFrom(ctx).error("find peer", "hostname", hostname, "network", network, "err", err)
+		}
 		return nil
 	}
 	return record
 }
 
-func readPeerRecordList(rows *sql.Rows) []*PeerRecord {
-	var recordList []*PeerRecord
-	defer rows.Close()
-	for rows.Next() {
-		if record := readPeerRecord(rows); record != nil {
-			recordList = append(recordList, record)
-		}
+func (s *server) updatePeerEndpoint(ctx context.Context, record *PeerRecord, endpoint, publicEndpoint string) *PeerRecord {
+	if err := s.store.UpdatePeerEndpoint(record.ID, endpoint, publicEndpoint); err != nil {
+		loggerFrom(ctx).withPeer(record).error("update the endpoint", "err", err)
+		return nil
 	}
-	return recordList
+	record.Endpoint, record.PublicEndpoint = endpoint, publicEndpoint
+	return record
 }
 
-func fetchSelfRecord(macAddress, hostname string) (record *PeerRecord) {
-	readFunc := func(col, value string) *PeerRecord {
-		queryStr := fmt.Sprintf("select * from wukuard where %s = ?", col)
-		rows, err := db.Query(queryStr, value)
-		checkErr(err)
-		defer rows.Close()
-		recordList := readPeerRecordList(rows)
-		if len(recordList) > 1 {
-			log.Printf("ERROR: duplicate %s: %s\n", col, value)
-			return nil
-		}
-		if len(recordList) <= 0 {
-			return nil
-		}
-		return recordList[0]
+// reportedEndpoints returns the endpoints of the client of req on the listen port of self:
+// the local address it reports, and the public address it discovered through STUN,
+// or else the address the server sees its requests from
+func reportedEndpoints(ctx context.Context, self *PeerRecord, req *pb.PeerRequest) (endpoint, public string) {
+	// the local address is unknown to clients which can't find it
+	host, port, _ := net.SplitHostPort(req.Endpoint)
+	if self.ListenPort != 0 {
+		// the server configures the port, whatever the client believes it is
+		port = strconv.Itoa(int(self.ListenPort))
 	}
-	colList := []string{"mac_address", "hostname", "token", "token"}
-	valueList := []string{macAddress, hostname, macAddress, hostname}
-	for i := range colList {
-		if record = readFunc(colList[i], valueList[i]); record != nil {
-			return
+	if port == "" {
+		return "", ""
+	}
+	if host != "" {
+		endpoint = net.JoinHostPort(host, port)
+	}
+	if ip := net.ParseIP(req.StunAddress); ip != nil {
+		return endpoint, net.JoinHostPort(ip.String(), port)
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if observed, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return endpoint, net.JoinHostPort(observed, port)
 		}
 	}
-	log.Printf("WARN: failed to fetch peer record: %s, %s\n", macAddress, hostname)
-	return
+	return endpoint, ""
 }
 
-func fetchAllRecords() []*PeerRecord {
-	rows, err := db.Query("select * from wukuard")
-	checkErr(err)
-	return readPeerRecordList(rows)
+func (s *server) updatePeerPublicKey(ctx context.Context, record *PeerRecord, publicKey string) *PeerRecord {
+	// the server only keeps the public key, drop any legacy private key at the same time
+	if err := s.store.UpdatePeerPublicKey(record.ID, publicKey); err != nil {
+		loggerFrom(ctx).withPeer(record).error("update the public key", "err", err)
+		return nil
+	}
+	record.PublicKey = publicKey
+	record.PrivateKey = ""
+	return record
 }
 
-func updatePeerEndpoint(record *PeerRecord, endpoint string) *PeerRecord {
-	_, err := db.Exec("update wukuard set endpoint=? where id=?", endpoint, record.id)
+// errPeerDeleted is returned to the clients whose peer was deleted, which then get a network marked as deleted
+var errPeerDeleted = status.Error(codes.NotFound, "peer deleted")
+
+// authenticate finds the peer by its credential,
+// or by the identity of its client certificate when mutual TLS is used without credential.
+// The peer must belong to network, unless network is empty.
+func (s *server) authenticate(ctx context.Context, network, credential string) (*PeerRecord, error) {
+	if credential == "" {
+		if network == "" {
+			network = defaultNetworkName
+		}
+		if identity, ok := certIdentity(ctx); ok {
+			return s.authenticateHostname(ctx, network, identity)
+		}
+		unknownPeersTotal.Inc()
+		return nil, status.Error(codes.Unauthenticated, "unknown credential")
+	}
+	// the token column stores the hash of the credential
+	tokenHash := hashSecret(credential)
+	self, err := s.store.GetPeerByToken(tokenHash)
+	if errors.Is(err, errNotFound) {
+		var deleted bool
+		if deleted, err = s.store.WasPeerDeleted(tokenHash); err == nil {
+			if deleted {
+				return nil, errPeerDeleted
+			}
+			unknownPeersTotal.Inc()
+			return nil, status.Error(codes.Unauthenticated, "unknown credential")
+		}
+	}
 	if err != nil {
-		checkErr(err)
-		return nil
+		// a client told that its credential is unknown would give up on it
+		loggerFrom(ctx).error("find the peer of the credential", "err", err)
+		return nil, status.Error(codes.Unavailable, "failed to read the peers")
 	}
-	record.endPoint = endpoint
-	return record
+	if network != "" && self.Network != network {
+		return nil, status.Errorf(codes.PermissionDenied, "not a peer of network %s", network)
+	}
+	if err := checkCertIdentity(ctx, self.Hostname); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return self, nil
 }
 
-func (s *server) HeartBeat(_ context.Context, req *pb.PeerRequest) (*pb.NetWorkResponse, error) {
-	resp := &pb.NetWorkResponse{}
+// authenticateHostname finds the peer named hostname in network, identified by its client certificate
+func (s *server) authenticateHostname(ctx context.Context, network, hostname string) (*PeerRecord, error) {
+	self, err := s.store.GetPeerByHostname(network, hostname)
+	if errors.Is(err, errNotFound) {
+		var deleted bool
+		if deleted, err = s.store.WasHostnameDeleted(network, hostname); err == nil {
+			if deleted {
+				return nil, errPeerDeleted
+			}
+			unknownPeersTotal.Inc()
+			return nil, status.Error(codes.Unauthenticated, "unknown credential")
+		}
+	}
+	if err != nil {
+		loggerFrom(ctx).error("find the peer of the certificate", "hostname", hostname, "network", network, "err", err)
+		return nil, status.Error(codes.Unavailable, "failed to read the peers")
+	}
+	return self, nil
+}
 
-	self := fetchSelfRecord(req.MacAddress, req.Hostname)
-	if self == nil {
-		return resp, nil
+// checkIn authenticates the peer and updates the info it reports about itself
+func (s *server) checkIn(ctx context.Context, req *pb.PeerRequest) (*PeerRecord, error) {
+	self, err := s.authenticate(ctx, req.Network, req.Credential)
+	if err != nil {
+		loggerFrom(ctx).warn("reject peer", "client_hostname", req.Hostname, "mac_address", req.MacAddress, "err", err)
+		return nil, err
 	}
-	if self.endPoint != req.Endpoint {
+	if endpoint, public := reportedEndpoints(ctx, self, req); self.Endpoint != endpoint || self.PublicEndpoint != public {
 		// update client peer info
-		self = updatePeerEndpoint(self, req.Endpoint)
+		self = s.updatePeerEndpoint(ctx, self, endpoint, public)
 		if self == nil {
-			return resp, nil
+			return nil, status.Error(codes.Internal, "failed to update endpoint")
 		}
 	}
-	resp.InterfaceResponse = &pb.InterfaceResponse{
-		PrivateKey: self.privateKey,
-		Address:    self.address,
-		ListenPort: self.listenPort,
-		PostUp:     self.postUP,
-		PreDown:    self.preDown,
-	}
-
-	// build response
-	records := fetchAllRecords()
-	peerList := make([]*pb.PeerResponse, 0)
-	for _, v := range records {
-		if v.id == self.id {
-			continue
+	s.markSeen(self, req.Version, req.Status)
+	s.saveStats(self, req.Stats)
+	if req.PublicKey != "" && self.PublicKey != req.PublicKey {
+		if err := validateKey(req.PublicKey); err != nil {
+			loggerFrom(ctx).withPeer(self).warn("invalid public key", "err", err)
+			return nil, status.Errorf(codes.InvalidArgument, "invalid public key: %s", err.Error())
 		}
-		peerList = append(peerList, &pb.PeerResponse{
-			Endpoint:            v.endPoint,
-			PublicKey:           v.publicKey,
-			AllowedIPs:          v.allowedIPs,
-			PersistentKeepalive: v.persistentKeepalive,
-		})
+		self = s.updatePeerPublicKey(ctx, self, req.PublicKey)
+		if self == nil {
+			return nil, status.Error(codes.Internal, "failed to update public key")
+		}
+	}
+	return self, nil
+}
+
+// buildNetwork returns the network of self as seen by self:
+// all the other peers of the network, or only those its policies let self talk to, through their relays
+func (s *server) buildNetwork(ctx context.Context, self *PeerRecord) (*pb.NetWorkResponse, error) {
+	log := loggerFrom(ctx).withPeer(self)
+	resp := &pb.NetWorkResponse{Network: self.Network}
+	resp.InterfaceResponse = &pb.InterfaceResponse{
+		Address:    self.Address,
+		ListenPort: self.ListenPort,
+		PostUp:     self.PostUp,
+		PreDown:    self.PreDown,
+		Relay:      self.Relay,
+		Routes:     self.Routes,
+		Id:         self.ID,
+	}
+	network, err := s.store.GetNetwork(self.Network)
+	if err != nil {
+		// an empty DNS would remove the DNS servers of the client
+		log.error("read the network", "err", err)
+		return nil, status.Error(codes.Unavailable, "failed to read the network")
 	}
+	resp.InterfaceResponse.Dns = network.DNS
 
-	resp.PeerList = peerList
+	policies, err := s.store.ListPolicies(self.Network)
+	if err != nil {
+		// never fall back to the full mesh
+		log.error("read the policies", "err", err)
+		return nil, status.Error(codes.Unavailable, "failed to read the policies")
+	}
+	records, err := s.store.ListPeers(self.Network)
+	if err != nil {
+		// an empty network would remove all the peers of the client
+		log.error("read the peers", "err", err)
+		return nil, status.Error(codes.Unavailable, "failed to read the peers")
+	}
+	records = s.settings.liveness().onlineRecords(records, time.Now())
+	resp.PeerList = buildPeerList(self, records, policies)
 	return resp, nil
 }
 
-func serverMain(confPath string) {
-	var err error
-	confPath, err = filepath.Abs(confPath)
+func (s *server) HeartBeat(ctx context.Context, req *pb.PeerRequest) (*pb.NetWorkResponse, error) {
+	defer prometheus.NewTimer(heartbeatDuration).ObserveDuration()
+	version, _ := s.broadcaster.current()
+	self, err := s.checkIn(ctx, req)
+	if err == errPeerDeleted {
+		return &pb.NetWorkResponse{Deleted: true}, nil
+	}
 	if err != nil {
-		panic("invalid config path")
+		return nil, err
 	}
-	if _, err = os.Stat(confPath); err != nil {
-		panic(fmt.Sprintf("invalid config path: %s", err.Error()))
+	resp, err := s.buildNetwork(ctx, self)
+	if err != nil {
+		return nil, err
+	}
+	heartbeatsTotal.WithLabelValues(self.Network, self.Hostname).Inc()
+	resp.Version = version
+	return resp, nil
+}
+
+func loadServerConfig(confPath string) (*ServerConfig, error) {
+	confPath, err := filepath.Abs(confPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config path: %w", err)
 	}
 	confFileBytes, err := os.ReadFile(confPath)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("invalid config path: %w", err)
 	}
 	conf := &ServerConfig{}
-	err = yaml.Unmarshal(confFileBytes, conf)
+	if err = yaml.Unmarshal(confFileBytes, conf); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return conf, nil
+}
+
+// openServerStore loads the config in confPath and opens the store it points to,
+// without the wrappers of the server, so that the store can be migrated
+func openServerStore(confPath string) (*ServerConfig, PeerStore, error) {
+	conf, err := loadServerConfig(confPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = configureLogging(conf.Log); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+	store, err := openStore(conf)
 	if err != nil {
-		panic(err)
+		return nil, nil, fmt.Errorf("open store: %w", err)
 	}
+	return conf, store, nil
+}
 
-	dbConf := conf.DB
-	db, err = sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/%s", dbConf.User, dbConf.Password, dbConf.Host, dbConf.Name))
-	if err != nil || db.Ping() != nil {
-		panic(err)
+// openServer loads the config in confPath and opens the server on the store it points to
+func openServer(confPath string) (*ServerConfig, *server, error) {
+	conf, store, err := openServerStore(confPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := newServer(store, newIPAM())
+	s.settings.set(conf)
+	return conf, s, nil
+}
+
+// migrateKeysMain moves the private keys still stored in the DB out to outDir,
+// one <hostname>.key file per peer, so that they can be installed on the clients.
+// The public keys are re-derived from the private keys and the private keys are removed from the DB.
+func migrateKeysMain(confPath, outDir string) error {
+	_, s, err := openServer(confPath)
+	if err != nil {
+		return err
+	}
+	defer s.store.Close()
+
+	if err = os.MkdirAll(outDir, 0700); err != nil {
+		return err
+	}
+	records, err := s.store.ListPeers("")
+	if err != nil {
+		return fmt.Errorf("list the peers: %w", err)
+	}
+	for _, record := range records {
+		if record.PrivateKey == "" {
+			continue
+		}
+		log := logger.withPeer(record)
+		publicKey, err := publicKeyOf(record.PrivateKey)
+		if err != nil {
+			log.error("invalid private key", "err", err)
+			continue
+		}
+		if publicKey != record.PublicKey {
+			log.warn("the public key does not match the private key, use the derived one")
+		}
+		keyFilename := filepath.Join(outDir, record.Hostname+".key")
+		if err = os.WriteFile(keyFilename, []byte(record.PrivateKey+"\n"), 0600); err != nil {
+			log.error("write the key", "file", keyFilename, "err", err)
+			continue
+		}
+		if s.updatePeerPublicKey(context.Background(), record, publicKey) == nil {
+			continue
+		}
+		log.info("migrated the key", "file", keyFilename)
+	}
+	return nil
+}
+
+func serverMain(confPath string) error {
+	conf, store, err := openServerStore(confPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	// the wrappers of the server don't migrate
+	if err = migrateStore(store); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	syncNet := newServer(store, newIPAM())
+	syncNet.settings.set(conf)
+	if err = ensureDefaultNetwork(syncNet.store, conf.Network); err != nil {
+		return fmt.Errorf("invalid network: %w", err)
 	}
 
 	if conf.Port == "" {
-		panic("invalid port")
+		return errors.New("no port in config")
 	}
 
 	lis, err := net.Listen("tcp", "0.0.0.0:"+conf.Port)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return fmt.Errorf("listen: %w", err)
+	}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(requestLoggerInterceptor, adminAuthInterceptor(syncNet.settings)),
+		grpc.StreamInterceptor(requestLoggerStreamInterceptor),
+	}
+	if conf.TLS.enabled() {
+		creds, err := conf.TLS.serverOption()
+		if err != nil {
+			return fmt.Errorf("load TLS config: %w", err)
+		}
+		opts = append(opts, creds)
+		logger.info("TLS enabled", "mutual", conf.TLS.mutual())
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterSyncNetServer(s, syncNet)
+	// share the store of SyncNet, so that the changes made by admins are pushed to the watchers
+	admin := newAdminServer(syncNet.store, syncNet.ipam)
+	admin.settings = syncNet.settings
+	pb.RegisterAdminServer(s, admin)
+	var gateway *http.Server
+	if conf.Admin.HTTPPort != "" {
+		gateway = &http.Server{Addr: "0.0.0.0:" + conf.Admin.HTTPPort, Handler: newAdminGateway(admin)}
+		go func() {
+			logger.info("admin gateway listening", "addr", gateway.Addr)
+			var err error
+			if conf.TLS.enabled() {
+				err = gateway.ListenAndServeTLS(conf.TLS.Cert, conf.TLS.Key)
+			} else {
+				err = gateway.ListenAndServe()
+			}
+			if err != http.ErrServerClosed {
+				logger.fatal("failed to serve admin gateway", "err", err)
+			}
+		}()
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	syncNet.stopping = ctx.Done()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	go watchHealth(ctx, store, healthServer)
+	reload := &serverReload{confPath: confPath, started: conf, server: syncNet, metricsAddr: conf.MetricsAddr}
+	reload.serveMetrics = func(addr string) (*http.Server, error) {
+		return serveMetrics(addr, serverMetrics, healthHandler(store))
 	}
-	s := grpc.NewServer()
-	pb.RegisterSyncNetServer(s, &server{})
-	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	if conf.MetricsAddr != "" {
+		if reload.metricsServer, err = reload.serveMetrics(conf.MetricsAddr); err != nil {
+			return fmt.Errorf("serve metrics: %w", err)
+		}
+	} else {
+		// the HTTP health checks share the listener of the metrics
+		logger.info("no metricsAddr, /healthz and /readyz are not served, only the gRPC health service")
+	}
+
+	signals := notifySignals()
+	defer signal.Stop(signals)
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(lis)
+	}()
+	logger.info("server listening", "addr", lis.Addr())
+	if err = sdNotify("READY=1"); err != nil {
+		logger.warn("tell systemd that the server is ready", "err", err)
+	}
+	for {
+		select {
+		case err = <-served:
+			return err
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				reload.reload()
+				continue
+			}
+			logger.info("stop the server", "signal", sig)
+			if err = sdNotify("STOPPING=1"); err != nil {
+				logger.warn("tell systemd that the server is stopping", "err", err)
+			}
+			// the streams never end by themselves, they would hold GracefulStop until the timeout
+			stop()
+			healthServer.Shutdown()
+			shutdownHTTP(gateway)
+			shutdownHTTP(reload.metricsServer)
+			gracefulStop(s)
+			<-served
+			logger.info("server stopped")
+			return nil
+		}
 	}
 }
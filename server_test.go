@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"testing"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// testNetwork is a server with the default network 10.0.0.0/24, whose peers are named after their credentials
+type testNetwork struct {
+	t      *testing.T
+	store  *memoryStore
+	server *server
+}
+
+func newTestNetwork(t *testing.T) *testNetwork {
+	t.Helper()
+	store := newMemoryStore()
+	if err := ensureDefaultNetwork(store, NetworkConfig{CIDR: "10.0.0.0/24", DNS: "9.9.9.9"}); err != nil {
+		t.Fatal(err)
+	}
+	return &testNetwork{t: t, store: store, server: newServer(store, newIPAM())}
+}
+
+// addPeer creates the peer hostname in network, authenticated by the credential hostname
+func (n *testNetwork) addPeer(network, hostname, address string) *PeerRecord {
+	n.t.Helper()
+	privateKey, err := generatePrivateKey()
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	publicKey, _ := publicKeyOf(privateKey)
+	ip, _, err := net.ParseCIDR(address)
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	record := &PeerRecord{
+		Network:    network,
+		Hostname:   hostname,
+		Token:      hashSecret(hostname),
+		PublicKey:  publicKey,
+		Address:    address,
+		AllowedIPs: ip.String() + "/32",
+		ListenPort: 9619,
+	}
+	if err = n.store.CreatePeer(record); err != nil {
+		n.t.Fatal(err)
+	}
+	return record
+}
+
+func (n *testNetwork) heartBeat(req *pb.PeerRequest) (*pb.NetWorkResponse, error) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.1"), Port: 40000}})
+	return n.server.HeartBeat(ctx, req)
+}
+
+func TestHeartBeat(t *testing.T) {
+	n := newTestNetwork(t)
+	n.addPeer("default", "a", "10.0.0.1/24")
+	b := n.addPeer("default", "b", "10.0.0.2/24")
+	n.addPeer("lab", "c", "10.0.0.3/24")
+
+	resp, err := n.heartBeat(&pb.PeerRequest{Credential: "a", Endpoint: "192.168.1.1:1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Network != "default" || resp.InterfaceResponse.Address != "10.0.0.1/24" || resp.InterfaceResponse.Dns != "9.9.9.9" {
+		t.Errorf("interface = %+v", resp.InterfaceResponse)
+	}
+	if len(resp.PeerList) != 1 || resp.PeerList[0].PublicKey != b.PublicKey || resp.PeerList[0].AllowedIPs != "10.0.0.2/32" {
+		t.Errorf("peers = %+v, want only b", resp.PeerList)
+	}
+
+	// the reported endpoints are saved on the listen port of the peer
+	self, _ := n.store.GetPeerByHostname("default", "a")
+	if self.Endpoint != "192.168.1.1:9619" || self.PublicEndpoint != "203.0.113.1:9619" {
+		t.Errorf("endpoints = %s, %s", self.Endpoint, self.PublicEndpoint)
+	}
+	if self.LastSeen == 0 {
+		t.Error("peer not marked as seen")
+	}
+}
+
+func TestCheckIn(t *testing.T) {
+	n := newTestNetwork(t)
+	n.addPeer("default", "a", "10.0.0.1/24")
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name string
+		req  *pb.PeerRequest
+		code codes.Code
+	}{
+		{"unknown credential", &pb.PeerRequest{Credential: "x"}, codes.Unauthenticated},
+		{"no credential", &pb.PeerRequest{}, codes.Unauthenticated},
+		{"other network", &pb.PeerRequest{Credential: "a", Network: "lab"}, codes.PermissionDenied},
+		{"invalid public key", &pb.PeerRequest{Credential: "a", PublicKey: "invalid"}, codes.InvalidArgument},
+	} {
+		if _, err := n.server.checkIn(ctx, test.req); status.Code(err) != test.code {
+			t.Errorf("%s: %v, want %s", test.name, err, test.code)
+		}
+	}
+
+	privateKey, _ := generatePrivateKey()
+	publicKey, _ := publicKeyOf(privateKey)
+	self, err := n.server.checkIn(ctx, &pb.PeerRequest{Credential: "a", Network: "default", PublicKey: publicKey, Version: "v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := n.store.GetPeer(self.ID); stored.PublicKey != publicKey || stored.ClientVersion != "v1" {
+		t.Errorf("stored peer = %+v", stored)
+	}
+}
+
+func TestHeartBeatDeletedPeer(t *testing.T) {
+	n := newTestNetwork(t)
+	a := n.addPeer("default", "a", "10.0.0.1/24")
+	if err := n.store.DeletePeer(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := n.heartBeat(&pb.PeerRequest{Credential: "a"})
+	if err != nil || !resp.Deleted || resp.InterfaceResponse != nil {
+		t.Errorf("heartbeat of a deleted peer = %+v, %v", resp, err)
+	}
+}
+
+func TestAuthenticateDeletedCertPeer(t *testing.T) {
+	n := newTestNetwork(t)
+	record := n.addPeer("default", "node1", "10.0.0.1/24")
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "node1"}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+
+	// without credential, the peer is the one named in the certificate
+	if self, err := n.server.authenticate(ctx, "", ""); err != nil || self.ID != record.ID {
+		t.Fatalf("authenticate by certificate = %+v, %v", self, err)
+	}
+	if err := n.store.DeletePeer(record.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.server.authenticate(ctx, "", ""); err != errPeerDeleted {
+		t.Errorf("authenticate the deleted peer = %v, want %v", err, errPeerDeleted)
+	}
+	if _, err := n.server.authenticate(ctx, "lab", ""); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("authenticate in another network = %v", err)
+	}
+}
+
+// failingStore fails the reads named in failing
+type failingStore struct {
+	PeerStore
+	failing string
+}
+
+var errStoreDown = errors.New("store down")
+
+func (s failingStore) GetNetwork(name string) (*NetworkRecord, error) {
+	if s.failing == "network" {
+		return nil, errStoreDown
+	}
+	return s.PeerStore.GetNetwork(name)
+}
+
+func (s failingStore) ListPeers(network string) ([]*PeerRecord, error) {
+	if s.failing == "peers" {
+		return nil, errStoreDown
+	}
+	return s.PeerStore.ListPeers(network)
+}
+
+func (s failingStore) ListPolicies(network string) ([]*PolicyRecord, error) {
+	if s.failing == "policies" {
+		return nil, errStoreDown
+	}
+	return s.PeerStore.ListPolicies(network)
+}
+
+func TestBuildNetworkStoreFailure(t *testing.T) {
+	n := newTestNetwork(t)
+	a := n.addPeer("default", "a", "10.0.0.1/24")
+	// a partial network would remove the DNS servers or the peers of the client
+	for _, failing := range []string{"network", "peers", "policies"} {
+		s := newServer(failingStore{PeerStore: n.store, failing: failing}, newIPAM())
+		if resp, err := s.buildNetwork(context.Background(), a); status.Code(err) != codes.Unavailable {
+			t.Errorf("%s unreadable: build network = %+v, %v", failing, resp, err)
+		}
+	}
+}
+
+func TestAdminUpdatePeerEndpoint(t *testing.T) {
+	n := newTestNetwork(t)
+	a := n.addPeer("default", "a", "10.0.0.1/24")
+	a.Endpoint = "203.0.113.1:40000"
+	if err := n.store.UpdatePeer(a); err != nil {
+		t.Fatal(err)
+	}
+	admin := newAdminServer(n.store, newIPAM())
+	req := &pb.UpdatePeerRequest{Peer: &pb.Peer{Id: a.ID, Hostname: "a", ListenPort: 9619, PostUp: "true"}}
+	if _, err := admin.UpdatePeer(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := n.store.GetPeer(a.ID)
+	if stored.Endpoint != "203.0.113.1:40000" || stored.PostUp != "true" {
+		t.Errorf("updated peer = %+v", stored)
+	}
+	req.Peer.Endpoint = "198.51.100.1:51820"
+	if _, err := admin.UpdatePeer(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ = n.store.GetPeer(a.ID); stored.Endpoint != "198.51.100.1:51820" {
+		t.Errorf("endpoint set by the admin = %s", stored.Endpoint)
+	}
+}
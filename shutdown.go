@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout bounds how long the calls in flight may take once the process is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// notifySignals relays SIGINT and SIGTERM, which stop the process, and SIGHUP, which reloads its config
+func notifySignals() chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	return signals
+}
+
+// gracefulStop lets the calls in flight finish, cutting them once shutdownTimeout has passed
+func gracefulStop(s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		logger.warn("calls still running after the shutdown timeout, cut them", "timeout", shutdownTimeout)
+		s.Stop()
+	}
+}
+
+// shutdownHTTP stops srv once the requests in flight are done, if it was started
+func shutdownHTTP(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.warn("stop serving", "addr", srv.Addr, "err", err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+)
+
+// maxReportedStats bounds the stats a client reports, one per peer of its network
+const maxReportedStats = 4096
+
+// statsFromPb converts the stats reported by a client at now
+func statsFromPb(stats []*pb.PeerStats, now int64) []*PeerStatsRecord {
+	records := make([]*PeerStatsRecord, 0, len(stats))
+	for _, peerStats := range stats {
+		if validateKey(peerStats.PublicKey) != nil {
+			continue
+		}
+		records = append(records, &PeerStatsRecord{
+			PublicKey:     peerStats.PublicKey,
+			Endpoint:      truncate(peerStats.Endpoint, 255),
+			LastHandshake: peerStats.LastHandshake,
+			RxBytes:       peerStats.RxBytes,
+			TxBytes:       peerStats.TxBytes,
+			ReportedAt:    now,
+		})
+	}
+	return records
+}
+
+// statsToPb converts the stats of a peer, naming the other ends of the tunnels with peers
+func statsToPb(records []*PeerStatsRecord, peers []*PeerRecord) []*pb.PeerStats {
+	byKey := make(map[string]*PeerRecord, len(peers))
+	for _, peer := range peers {
+		byKey[peer.PublicKey] = peer
+	}
+	stats := make([]*pb.PeerStats, 0, len(records))
+	for _, record := range records {
+		peerStats := &pb.PeerStats{
+			PublicKey:     record.PublicKey,
+			Endpoint:      record.Endpoint,
+			LastHandshake: record.LastHandshake,
+			RxBytes:       record.RxBytes,
+			TxBytes:       record.TxBytes,
+			ReportedAt:    record.ReportedAt,
+		}
+		if peer, ok := byKey[record.PublicKey]; ok {
+			peerStats.PeerId, peerStats.Hostname = peer.ID, peer.Hostname
+		}
+		stats = append(stats, peerStats)
+	}
+	return stats
+}
+
+// saveStats records the stats reported by the client of self
+func (s *server) saveStats(self *PeerRecord, stats []*pb.PeerStats) {
+	if len(stats) <= 0 {
+		return
+	}
+	if len(stats) > maxReportedStats {
+		stats = stats[:maxReportedStats]
+	}
+	if err := s.store.UpdatePeerStats(self.ID, statsFromPb(stats, time.Now().Unix())); err != nil {
+		logger.withPeer(self).error("save the stats", "err", err)
+	}
+}
+
+// statsToReport reads the stats of the tunnels from the device, to be reported to the server
+func (a *agent) statsToReport() []*pb.PeerStats {
+	deviceStats, err := a.backend.Stats()
+	if err != nil {
+		a.log.warn("read the stats of the device", "err", err)
+		return nil
+	}
+	stats := make([]*pb.PeerStats, 0, len(deviceStats))
+	for _, peerStats := range deviceStats {
+		var lastHandshake int64
+		if !peerStats.LastHandshake.IsZero() {
+			lastHandshake = peerStats.LastHandshake.Unix()
+		}
+		stats = append(stats, &pb.PeerStats{
+			PublicKey:     peerStats.PublicKey,
+			Endpoint:      peerStats.Endpoint,
+			LastHandshake: lastHandshake,
+			RxBytes:       peerStats.RxBytes,
+			TxBytes:       peerStats.TxBytes,
+		})
+	}
+	return stats
+}
+
+func (s *adminServer) GetPeerStats(_ context.Context, req *pb.GetPeerStatsRequest) (*pb.GetPeerStatsResponse, error) {
+	record, err := s.store.GetPeer(req.Id)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	records, err := s.store.ListPeerStats(record.ID)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	peers, err := s.store.ListPeers(record.Network)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.GetPeerStatsResponse{Stats: statsToPb(records, peers)}, nil
+}
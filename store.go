@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errNotFound = errors.New("not found")
+
+// defaultNetworkName is the network of the peers which don't name one,
+// such as the peers created before the server had several networks
+const defaultNetworkName = "default"
+
+// NetworkRecord is an isolated mesh, its peers only see the other peers of the same network
+type NetworkRecord struct {
+	ID    int32  `json:"id"`
+	Name  string `json:"name"`
+	CIDR  string `json:"cidr"`
+	CIDR6 string `json:"cidr6"`
+	// DNS is the comma separated DNS servers of the interfaces
+	DNS string `json:"dns"`
+	// ListenPort and PersistentKeepalive are the defaults of the new peers
+	ListenPort          int32 `json:"listenPort"`
+	PersistentKeepalive int32 `json:"persistentKeepalive"`
+	CreatedAt           int64 `json:"createdAt"`
+	UpdatedAt           int64 `json:"updatedAt"`
+}
+
+// PeerRecord is a peer of the network as kept by the PeerStore
+type PeerRecord struct {
+	ID int32 `json:"id"`
+	// Network is the name of the network of the peer, a host joins several networks with one peer per network
+	Network    string `json:"network"`
+	MacAddress string `json:"macAddress"`
+	Hostname   string `json:"hostname"`
+	// Token is the hash of the credential of the peer
+	Token      string `json:"token"`
+	PublicKey  string `json:"publicKey"`
+	PrivateKey string `json:"privateKey,omitempty"` // legacy, only kept until migrate-keys
+	PostUp     string `json:"postUp"`
+	PreDown    string `json:"preDown"`
+	Address    string `json:"address"`
+	ListenPort int32  `json:"listenPort"`
+	Endpoint   string `json:"endpoint"`
+	// PublicEndpoint is the public address the peer is seen from, with its listen port,
+	// which the peers behind other NATs use instead of Endpoint
+	PublicEndpoint      string `json:"publicEndpoint"`
+	AllowedIPs          string `json:"allowedIPs"`
+	PersistentKeepalive int32  `json:"persistentKeepalive"`
+	// Tags are the comma separated groups of the peer, which policies refer to
+	Tags string `json:"tags"`
+	// Relay is true if the peer may forward the traffic of other peers
+	Relay bool `json:"relay"`
+	// RelayedBy is the id of the relay all the traffic of the peer goes through, if not 0
+	RelayedBy int32 `json:"relayedBy"`
+	// Routes are the comma separated subnets, such as LANs or a default route, the peer advertises as a gateway
+	Routes string `json:"routes"`
+	// UseGateway is true if the peer sends its traffic to the internet through the default route of its network
+	UseGateway bool `json:"useGateway"`
+	// LastSeen is when the server last heard from the client of the peer, in unix seconds, 0 if never
+	LastSeen int64 `json:"lastSeen"`
+	// ClientVersion and Status are the last ones reported by the client
+	ClientVersion string `json:"clientVersion"`
+	Status        string `json:"status"`
+	CreatedAt     int64  `json:"createdAt"`
+	UpdatedAt     int64  `json:"updatedAt"`
+}
+
+// PeerStatsRecord is the state of the tunnel from a peer to another, as reported by the client of the first one
+type PeerStatsRecord struct {
+	PeerID int32 `json:"peerId"`
+	// PublicKey is the key of the other end of the tunnel
+	PublicKey string `json:"publicKey"`
+	// Endpoint is the one the device currently uses, which may have roamed
+	Endpoint string `json:"endpoint"`
+	// LastHandshake is in unix seconds, 0 if no handshake happened yet
+	LastHandshake int64 `json:"lastHandshake"`
+	RxBytes       int64 `json:"rxBytes"`
+	TxBytes       int64 `json:"txBytes"`
+	ReportedAt    int64 `json:"reportedAt"`
+}
+
+// PolicyRecord lets the peers tagged with one of From reach the peers tagged with one of To in Network,
+// only on CIDRs if set
+type PolicyRecord struct {
+	ID          int32  `json:"id"`
+	Network     string `json:"network"`
+	Description string `json:"description"`
+	// From and To are comma separated tags, * matches every peer
+	From      string `json:"from"`
+	To        string `json:"to"`
+	CIDRs     string `json:"cidrs"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// JoinToken is a one-time token minted for the peer named Hostname in Network
+type JoinToken struct {
+	ID        int64  `json:"id"`
+	TokenHash string `json:"tokenHash"`
+	Network   string `json:"network"`
+	Hostname  string `json:"hostname"`
+	ExpiresAt int64  `json:"expiresAt"`
+	UsedAt    int64  `json:"usedAt,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// PeerStore keeps the networks, the peers and the join tokens of the server.
+// The getters return errNotFound if nothing matches.
+type PeerStore interface {
+	GetNetwork(name string) (*NetworkRecord, error)
+	ListNetworks() ([]*NetworkRecord, error)
+	// CreateNetwork saves a new network and sets its ID
+	CreateNetwork(record *NetworkRecord) error
+	// UpdateNetwork replaces the fields of the network named record.Name
+	UpdateNetwork(record *NetworkRecord) error
+	DeleteNetwork(name string) error
+
+	GetPeer(id int32) (*PeerRecord, error)
+	GetPeerByToken(tokenHash string) (*PeerRecord, error)
+	GetPeerByHostname(network, hostname string) (*PeerRecord, error)
+	// ListPeers lists the peers of network, or of all the networks if network is empty
+	ListPeers(network string) ([]*PeerRecord, error)
+
+	// CreatePeer saves a new peer and sets its ID
+	CreatePeer(record *PeerRecord) error
+	// UpdatePeer replaces the fields managed by the admin of the peer with record.ID
+	UpdatePeer(record *PeerRecord) error
+	// DeletePeer removes the peer and remembers its credential hash and its hostname, see WasPeerDeleted
+	DeletePeer(id int32) error
+	// WasPeerDeleted tells whether tokenHash is the credential hash of a deleted peer
+	WasPeerDeleted(tokenHash string) (bool, error)
+	// WasHostnameDeleted tells whether a peer named hostname was deleted from network,
+	// for the clients authenticated by their certificate alone
+	WasHostnameDeleted(network, hostname string) (bool, error)
+
+	// UpdatePeerEndpoint sets the local and public endpoints reported by the peer
+	UpdatePeerEndpoint(id int32, endpoint, publicEndpoint string) error
+	// UpdatePeerSeen records when the server last heard from the client of the peer and what it reported
+	UpdatePeerSeen(id int32, lastSeen int64, clientVersion, status string) error
+	// UpdatePeerStats replaces the tunnel stats reported by the client of the peer
+	UpdatePeerStats(id int32, stats []*PeerStatsRecord) error
+	// ListPeerStats lists the tunnel stats last reported by the client of the peer
+	ListPeerStats(id int32) ([]*PeerStatsRecord, error)
+	// UpdatePeerPublicKey sets the public key and drops the legacy private key of the peer
+	UpdatePeerPublicKey(id int32, publicKey string) error
+	// UpdatePeerToken sets the credential hash and the public key of the peer
+	UpdatePeerToken(id int32, tokenHash, publicKey string) error
+
+	GetPolicy(id int32) (*PolicyRecord, error)
+	// ListPolicies lists the policies of network, or of all the networks if network is empty
+	ListPolicies(network string) ([]*PolicyRecord, error)
+	// CreatePolicy saves a new policy and sets its ID
+	CreatePolicy(record *PolicyRecord) error
+	// UpdatePolicy replaces the fields of the policy with record.ID
+	UpdatePolicy(record *PolicyRecord) error
+	DeletePolicy(id int32) error
+
+	CreateJoinToken(token *JoinToken) error
+	// ConsumeJoinToken marks the unused token as used at now and returns it
+	ConsumeJoinToken(tokenHash string, now int64) (*JoinToken, error)
+
+	Close() error
+}
+
+type StoreConfig struct {
+	// Type is one of mysql, file and memory, mysql by default
+	Type string `yaml:"type"`
+	// Path is the file of the file store
+	Path string `yaml:"path"`
+}
+
+func openStore(conf *ServerConfig) (PeerStore, error) {
+	switch conf.Store.Type {
+	case "", "mysql":
+		return openMySQLStore(conf.DB)
+	case "file":
+		if conf.Store.Path == "" {
+			return nil, errors.New("no path for the file store")
+		}
+		return openFileStore(conf.Store.Path)
+	case "memory":
+		return newMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store type: %s", conf.Store.Type)
+	}
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps everything in memory.
+// With a filename, it is the file store: loaded from and saved to a JSON file after every change.
+type memoryStore struct {
+	mu       sync.RWMutex
+	filename string
+
+	Networks   []*NetworkRecord `json:"networks"`
+	Peers      []*PeerRecord    `json:"peers"`
+	Policies   []*PolicyRecord  `json:"policies"`
+	JoinTokens []*JoinToken     `json:"joinTokens"`
+	// Stats are the tunnel stats of the peers, by peer ID
+	Stats map[int32][]*PeerStatsRecord `json:"stats,omitempty"`
+	// DeletedTokens are the credential hashes of the deleted peers, with when they were deleted
+	DeletedTokens map[string]int64 `json:"deletedTokens,omitempty"`
+	// DeletedHostnames are the network/hostname of the deleted peers, with when they were deleted
+	DeletedHostnames map[string]int64 `json:"deletedHostnames,omitempty"`
+	// LastIDs are the last IDs given to the records of each kind, which are never given again, as with AUTO_INCREMENT
+	LastIDs lastIDs `json:"lastIds"`
+}
+
+type lastIDs struct {
+	Network   int32 `json:"network"`
+	Peer      int32 `json:"peer"`
+	Policy    int32 `json:"policy"`
+	JoinToken int64 `json:"joinToken"`
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{}
+}
+
+func openFileStore(filename string) (*memoryStore, error) {
+	s := &memoryStore{filename: filename}
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, s.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, s); err != nil {
+		return nil, err
+	}
+	// files written before networks keep everything in the default one
+	for _, record := range s.Peers {
+		if record.Network == "" {
+			record.Network = defaultNetworkName
+		}
+	}
+	for _, token := range s.JoinTokens {
+		if token.Network == "" {
+			token.Network = defaultNetworkName
+		}
+	}
+	return s, nil
+}
+
+// save writes the store to its file, callers must hold the write lock
+func (s *memoryStore) save() error {
+	if s.filename == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.filename), 0700); err != nil {
+		return err
+	}
+	tmpFilename := s.filename + ".tmp"
+	if err = os.WriteFile(tmpFilename, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilename, s.filename)
+}
+
+func (s *memoryStore) GetNetwork(name string) (*NetworkRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, record := range s.Networks {
+		if record.Name == name {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (s *memoryStore) ListNetworks() ([]*NetworkRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	recordList := make([]*NetworkRecord, 0, len(s.Networks))
+	for _, record := range s.Networks {
+		copied := *record
+		recordList = append(recordList, &copied)
+	}
+	sort.Slice(recordList, func(i, j int) bool {
+		return recordList[i].ID < recordList[j].ID
+	})
+	return recordList, nil
+}
+
+func (s *memoryStore) CreateNetwork(record *NetworkRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the files written before LastIDs only know the IDs of the records left
+	record.ID = s.LastIDs.Network + 1
+	for _, v := range s.Networks {
+		if v.Name == record.Name {
+			return fmt.Errorf("duplicate network: %s", record.Name)
+		}
+		if v.ID >= record.ID {
+			record.ID = v.ID + 1
+		}
+	}
+	s.LastIDs.Network = record.ID
+	copied := *record
+	s.Networks = append(s.Networks, &copied)
+	return s.save()
+}
+
+func (s *memoryStore) UpdateNetwork(record *NetworkRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.Networks {
+		if v.Name == record.Name {
+			v.CIDR, v.CIDR6, v.DNS = record.CIDR, record.CIDR6, record.DNS
+			v.ListenPort, v.PersistentKeepalive = record.ListenPort, record.PersistentKeepalive
+			v.UpdatedAt = record.UpdatedAt
+			return s.save()
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) DeleteNetwork(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, record := range s.Networks {
+		if record.Name == name {
+			s.Networks = append(s.Networks[:i], s.Networks[i+1:]...)
+			return s.save()
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) findPeer(match func(*PeerRecord) bool) (*PeerRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, record := range s.Peers {
+		if match(record) {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (s *memoryStore) GetPeer(id int32) (*PeerRecord, error) {
+	return s.findPeer(func(record *PeerRecord) bool {
+		return record.ID == id
+	})
+}
+
+func (s *memoryStore) GetPeerByToken(tokenHash string) (*PeerRecord, error) {
+	return s.findPeer(func(record *PeerRecord) bool {
+		return tokenHash != "" && record.Token == tokenHash
+	})
+}
+
+func (s *memoryStore) GetPeerByHostname(network, hostname string) (*PeerRecord, error) {
+	return s.findPeer(func(record *PeerRecord) bool {
+		return record.Network == network && record.Hostname == hostname
+	})
+}
+
+func (s *memoryStore) ListPeers(network string) ([]*PeerRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	recordList := make([]*PeerRecord, 0, len(s.Peers))
+	for _, record := range s.Peers {
+		if network != "" && record.Network != network {
+			continue
+		}
+		copied := *record
+		recordList = append(recordList, &copied)
+	}
+	sort.Slice(recordList, func(i, j int) bool {
+		return recordList[i].ID < recordList[j].ID
+	})
+	return recordList, nil
+}
+
+func (s *memoryStore) CreatePeer(record *PeerRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.ID = s.LastIDs.Peer + 1
+	for _, v := range s.Peers {
+		if v.ID >= record.ID {
+			record.ID = v.ID + 1
+		}
+	}
+	s.LastIDs.Peer = record.ID
+	copied := *record
+	s.Peers = append(s.Peers, &copied)
+	return s.save()
+}
+
+func (s *memoryStore) UpdatePeer(record *PeerRecord) error {
+	return s.updatePeer(record.ID, func(v *PeerRecord) {
+		v.Network, v.MacAddress, v.Hostname, v.PublicKey = record.Network, record.MacAddress, record.Hostname, record.PublicKey
+		v.PostUp, v.PreDown = record.PostUp, record.PreDown
+		v.Address, v.ListenPort, v.Endpoint = record.Address, record.ListenPort, record.Endpoint
+		v.AllowedIPs, v.PersistentKeepalive = record.AllowedIPs, record.PersistentKeepalive
+		v.Tags, v.Relay, v.RelayedBy = record.Tags, record.Relay, record.RelayedBy
+		v.Routes, v.UseGateway = record.Routes, record.UseGateway
+		v.UpdatedAt = record.UpdatedAt
+	})
+}
+
+func (s *memoryStore) DeletePeer(id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, record := range s.Peers {
+		if record.ID == id {
+			s.Peers = append(s.Peers[:i], s.Peers[i+1:]...)
+			delete(s.Stats, id)
+			if record.Token != "" {
+				if s.DeletedTokens == nil {
+					s.DeletedTokens = make(map[string]int64)
+				}
+				s.DeletedTokens[record.Token] = time.Now().Unix()
+			}
+			if s.DeletedHostnames == nil {
+				s.DeletedHostnames = make(map[string]int64)
+			}
+			s.DeletedHostnames[record.Network+"/"+record.Hostname] = time.Now().Unix()
+			return s.save()
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) WasPeerDeleted(tokenHash string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.DeletedTokens[tokenHash]
+	return ok, nil
+}
+
+func (s *memoryStore) WasHostnameDeleted(network, hostname string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.DeletedHostnames[network+"/"+hostname]
+	return ok, nil
+}
+
+// updatePeer applies update to the peer with id and saves the store
+func (s *memoryStore) updatePeer(id int32, update func(*PeerRecord)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range s.Peers {
+		if record.ID == id {
+			update(record)
+			return s.save()
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) UpdatePeerEndpoint(id int32, endpoint, publicEndpoint string) error {
+	return s.updatePeer(id, func(record *PeerRecord) {
+		record.Endpoint, record.PublicEndpoint = endpoint, publicEndpoint
+	})
+}
+
+func (s *memoryStore) UpdatePeerSeen(id int32, lastSeen int64, clientVersion, status string) error {
+	return s.updatePeer(id, func(record *PeerRecord) {
+		record.LastSeen, record.ClientVersion, record.Status = lastSeen, clientVersion, status
+	})
+}
+
+func (s *memoryStore) UpdatePeerStats(id int32, stats []*PeerStatsRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Stats == nil {
+		s.Stats = make(map[int32][]*PeerStatsRecord)
+	}
+	copied := make([]*PeerStatsRecord, 0, len(stats))
+	for _, record := range stats {
+		record := *record
+		record.PeerID = id
+		copied = append(copied, &record)
+	}
+	s.Stats[id] = copied
+	return s.save()
+}
+
+func (s *memoryStore) ListPeerStats(id int32) ([]*PeerStatsRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var stats []*PeerStatsRecord
+	for _, record := range s.Stats[id] {
+		copied := *record
+		stats = append(stats, &copied)
+	}
+	return stats, nil
+}
+
+func (s *memoryStore) UpdatePeerPublicKey(id int32, publicKey string) error {
+	return s.updatePeer(id, func(record *PeerRecord) {
+		record.PublicKey, record.PrivateKey = publicKey, ""
+	})
+}
+
+func (s *memoryStore) UpdatePeerToken(id int32, tokenHash, publicKey string) error {
+	return s.updatePeer(id, func(record *PeerRecord) {
+		record.Token = tokenHash
+		record.PublicKey, record.PrivateKey = publicKey, ""
+	})
+}
+
+func (s *memoryStore) GetPolicy(id int32) (*PolicyRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, record := range s.Policies {
+		if record.ID == id {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (s *memoryStore) ListPolicies(network string) ([]*PolicyRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	recordList := make([]*PolicyRecord, 0, len(s.Policies))
+	for _, record := range s.Policies {
+		if network != "" && record.Network != network {
+			continue
+		}
+		copied := *record
+		recordList = append(recordList, &copied)
+	}
+	sort.Slice(recordList, func(i, j int) bool {
+		return recordList[i].ID < recordList[j].ID
+	})
+	return recordList, nil
+}
+
+func (s *memoryStore) CreatePolicy(record *PolicyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.ID = s.LastIDs.Policy + 1
+	for _, v := range s.Policies {
+		if v.ID >= record.ID {
+			record.ID = v.ID + 1
+		}
+	}
+	s.LastIDs.Policy = record.ID
+	copied := *record
+	s.Policies = append(s.Policies, &copied)
+	return s.save()
+}
+
+func (s *memoryStore) UpdatePolicy(record *PolicyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.Policies {
+		if v.ID == record.ID {
+			v.Network, v.Description = record.Network, record.Description
+			v.From, v.To, v.CIDRs = record.From, record.To, record.CIDRs
+			v.UpdatedAt = record.UpdatedAt
+			return s.save()
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) DeletePolicy(id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, record := range s.Policies {
+		if record.ID == id {
+			s.Policies = append(s.Policies[:i], s.Policies[i+1:]...)
+			return s.save()
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) CreateJoinToken(token *JoinToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token.ID = s.LastIDs.JoinToken + 1
+	for _, v := range s.JoinTokens {
+		if v.ID >= token.ID {
+			token.ID = v.ID + 1
+		}
+	}
+	s.LastIDs.JoinToken = token.ID
+	copied := *token
+	s.JoinTokens = append(s.JoinTokens, &copied)
+	return s.save()
+}
+
+func (s *memoryStore) ConsumeJoinToken(tokenHash string, now int64) (*JoinToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range s.JoinTokens {
+		if token.TokenHash == tokenHash && token.UsedAt == 0 {
+			token.UsedAt = now
+			copied := *token
+			return &copied, s.save()
+		}
+	}
+	return nil, errNotFound
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreNetworks(t *testing.T) {
+	store := newMemoryStore()
+	if _, err := store.GetNetwork("lab"); !errors.Is(err, errNotFound) {
+		t.Fatalf("get missing network: %v", err)
+	}
+	for _, name := range []string{"default", "lab"} {
+		if err := store.CreateNetwork(&NetworkRecord{Name: name, CIDR: "10.0.0.0/24"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.CreateNetwork(&NetworkRecord{Name: "lab"}); err == nil {
+		t.Error("duplicate network created")
+	}
+	if err := store.UpdateNetwork(&NetworkRecord{Name: "lab", CIDR: "10.1.0.0/24", DNS: "9.9.9.9"}); err != nil {
+		t.Fatal(err)
+	}
+	network, err := store.GetNetwork("lab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if network.ID != 2 || network.CIDR != "10.1.0.0/24" || network.DNS != "9.9.9.9" {
+		t.Errorf("network = %+v", network)
+	}
+	// the records returned are copies
+	network.DNS = "1.1.1.1"
+	if network, _ = store.GetNetwork("lab"); network.DNS != "9.9.9.9" {
+		t.Error("the store shares its records")
+	}
+
+	if err = store.DeleteNetwork("lab"); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.UpdateNetwork(&NetworkRecord{Name: "lab"}); !errors.Is(err, errNotFound) {
+		t.Errorf("update deleted network: %v", err)
+	}
+	networks, _ := store.ListNetworks()
+	if len(networks) != 1 || networks[0].Name != "default" {
+		t.Errorf("networks = %+v", networks)
+	}
+	network = &NetworkRecord{Name: "ops", CIDR: "10.2.0.0/24"}
+	if err = store.CreateNetwork(network); err != nil || network.ID != 3 {
+		t.Errorf("network created after the deletion of the last one = %+v, %v", network, err)
+	}
+}
+
+func TestMemoryStorePeers(t *testing.T) {
+	store := newMemoryStore()
+	for _, record := range []*PeerRecord{
+		{Network: "default", Hostname: "a", Token: "hash-a"},
+		{Network: "default", Hostname: "b"},
+		{Network: "lab", Hostname: "a"},
+	} {
+		if err := store.CreatePeer(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record, err := store.GetPeerByToken("hash-a")
+	if err != nil || record.ID != 1 {
+		t.Fatalf("by token = %+v, %v", record, err)
+	}
+	if _, err = store.GetPeerByToken(""); !errors.Is(err, errNotFound) {
+		t.Errorf("a peer without credential matches the empty one: %v", err)
+	}
+	if record, err = store.GetPeerByHostname("lab", "a"); err != nil || record.ID != 3 {
+		t.Errorf("by hostname = %+v, %v", record, err)
+	}
+	if records, _ := store.ListPeers("default"); len(records) != 2 {
+		t.Errorf("peers of default = %d, want 2", len(records))
+	}
+	if records, _ := store.ListPeers(""); len(records) != 3 {
+		t.Errorf("all peers = %d, want 3", len(records))
+	}
+
+	record.Address = "10.0.0.3/24"
+	if err = store.UpdatePeer(record); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.UpdatePeerEndpoint(3, "192.168.1.3:9619", "203.0.113.3:9619"); err != nil {
+		t.Fatal(err)
+	}
+	if record, _ = store.GetPeer(3); record.Address != "10.0.0.3/24" || record.PublicEndpoint != "203.0.113.3:9619" {
+		t.Errorf("updated peer = %+v", record)
+	}
+	if err = store.UpdatePeerEndpoint(42, "", ""); !errors.Is(err, errNotFound) {
+		t.Errorf("update missing peer: %v", err)
+	}
+}
+
+func TestMemoryStoreDeletedPeers(t *testing.T) {
+	store := newMemoryStore()
+	if err := store.CreatePeer(&PeerRecord{Network: "default", Hostname: "a", Token: "hash-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, _ := store.WasPeerDeleted("hash-a"); deleted {
+		t.Error("live peer reported deleted")
+	}
+	if err := store.DeletePeer(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetPeer(1); !errors.Is(err, errNotFound) {
+		t.Errorf("get deleted peer: %v", err)
+	}
+	if deleted, _ := store.WasPeerDeleted("hash-a"); !deleted {
+		t.Error("deleted peer not reported")
+	}
+	if deleted, _ := store.WasHostnameDeleted("default", "a"); !deleted {
+		t.Error("deleted hostname not reported")
+	}
+	if deleted, _ := store.WasHostnameDeleted("lab", "a"); deleted {
+		t.Error("hostname reported deleted from another network")
+	}
+	if err := store.DeletePeer(1); !errors.Is(err, errNotFound) {
+		t.Errorf("delete twice: %v", err)
+	}
+}
+
+func TestMemoryStoreJoinTokens(t *testing.T) {
+	store := newMemoryStore()
+	now := time.Now().Unix()
+	if err := store.CreateJoinToken(&JoinToken{TokenHash: "hash", Network: "default", Hostname: "a", ExpiresAt: now + 60}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := store.ConsumeJoinToken("hash", now)
+	if err != nil || token.Hostname != "a" || token.UsedAt != now {
+		t.Fatalf("consume = %+v, %v", token, err)
+	}
+	if _, err = store.ConsumeJoinToken("hash", now); !errors.Is(err, errNotFound) {
+		t.Errorf("token consumed twice: %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "store", "wukuard.json")
+	store, err := openFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.CreateNetwork(&NetworkRecord{Name: "default", CIDR: "10.0.0.0/24"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.CreatePeer(&PeerRecord{Network: "default", Hostname: "a", Token: "hash-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.DeletePeer(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.CreatePeer(&PeerRecord{Network: "default", Hostname: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := openFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reopened.GetNetwork("default"); err != nil {
+		t.Errorf("network lost: %v", err)
+	}
+	// the IDs of the deleted records are never given again
+	if record, err := reopened.GetPeerByHostname("default", "b"); err != nil || record.ID != 2 {
+		t.Errorf("peer = %+v, %v", record, err)
+	}
+	if err = reopened.DeletePeer(2); err != nil {
+		t.Fatal(err)
+	}
+	record := &PeerRecord{Network: "default", Hostname: "c"}
+	if err = reopened.CreatePeer(record); err != nil || record.ID != 3 {
+		t.Errorf("peer created after the deletions = %+v, %v", record, err)
+	}
+	if deleted, _ := reopened.WasPeerDeleted("hash-a"); !deleted {
+		t.Error("deletion lost")
+	}
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Name     string `yaml:"name"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
+
+type mysqlStore struct {
+	db *sql.DB
+}
+
+func openMySQLStore(dbConf DBConfig) (*mysqlStore, error) {
+	// clientFoundRows makes updates report the rows matched rather than changed, which checkAffected relies on
+	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/%s?clientFoundRows=true", dbConf.User, dbConf.Password, dbConf.Host, dbConf.Name))
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &mysqlStore{db: db}, nil
+}
+
+// peerColumns must be kept in the order readPeerRecord scans them
+const peerColumns = "id, network, mac_address, hostname, token, public_key, private_key, post_up, pre_down, " +
+	"address, listen_port, endpoint, public_endpoint, allowed_ips, persistent_keepalive, tags, relay, relayed_by, routes, use_gateway, " +
+	"last_seen, client_version, status, created_at, updated_at"
+
+func (s *mysqlStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *mysqlStore) Migrate() error {
+	return migrateDB(s.db)
+}
+
+func readPeerRecord(rows *sql.Rows) *PeerRecord {
+	var macAddress, token sql.NullString
+	record := new(PeerRecord)
+	err := rows.Scan(
+		&(record.ID),
+		&(record.Network),
+		&macAddress,
+		&(record.Hostname),
+		&token,
+		&(record.PublicKey),
+		&(record.PrivateKey),
+		&(record.PostUp),
+		&(record.PreDown),
+		&(record.Address),
+		&(record.ListenPort),
+		&(record.Endpoint),
+		&(record.PublicEndpoint),
+		&(record.AllowedIPs),
+		&(record.PersistentKeepalive),
+		&(record.Tags),
+		&(record.Relay),
+		&(record.RelayedBy),
+		&(record.Routes),
+		&(record.UseGateway),
+		&(record.LastSeen),
+		&(record.ClientVersion),
+		&(record.Status),
+		&(record.CreatedAt),
+		&(record.UpdatedAt),
+	)
+	if err != nil {
+		logger.error("read a peer from the DB", "err", err)
+		return nil
+	}
+	record.MacAddress, record.Token = macAddress.String, token.String
+	return record
+}
+
+func readPeerRecordList(rows *sql.Rows) []*PeerRecord {
+	var recordList []*PeerRecord
+	defer rows.Close()
+	for rows.Next() {
+		if record := readPeerRecord(rows); record != nil {
+			recordList = append(recordList, record)
+		}
+	}
+	return recordList
+}
+
+// networkColumns must be kept in the order readNetworkRecord scans them
+const networkColumns = "id, name, cidr, cidr6, dns, listen_port, persistent_keepalive, created_at, updated_at"
+
+func readNetworkRecordList(rows *sql.Rows) []*NetworkRecord {
+	var recordList []*NetworkRecord
+	defer rows.Close()
+	for rows.Next() {
+		record := new(NetworkRecord)
+		err := rows.Scan(&(record.ID), &(record.Name), &(record.CIDR), &(record.CIDR6), &(record.DNS),
+			&(record.ListenPort), &(record.PersistentKeepalive), &(record.CreatedAt), &(record.UpdatedAt))
+		if err != nil {
+			logger.error("read a network from the DB", "err", err)
+			continue
+		}
+		recordList = append(recordList, record)
+	}
+	return recordList
+}
+
+func (s *mysqlStore) GetNetwork(name string) (*NetworkRecord, error) {
+	rows, err := s.db.Query("select "+networkColumns+" from wukuard_network where name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	recordList := readNetworkRecordList(rows)
+	if len(recordList) <= 0 {
+		return nil, errNotFound
+	}
+	return recordList[0], nil
+}
+
+func (s *mysqlStore) ListNetworks() ([]*NetworkRecord, error) {
+	rows, err := s.db.Query("select " + networkColumns + " from wukuard_network order by id")
+	if err != nil {
+		return nil, err
+	}
+	return readNetworkRecordList(rows), nil
+}
+
+func (s *mysqlStore) CreateNetwork(record *NetworkRecord) error {
+	result, err := s.db.Exec("insert into wukuard_network (name, cidr, cidr6, dns, listen_port, persistent_keepalive, "+
+		"created_at, updated_at) values (?, ?, ?, ?, ?, ?, ?, ?)",
+		record.Name, record.CIDR, record.CIDR6, record.DNS, record.ListenPort, record.PersistentKeepalive, record.CreatedAt, record.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	record.ID = int32(id)
+	return nil
+}
+
+func (s *mysqlStore) UpdateNetwork(record *NetworkRecord) error {
+	result, err := s.db.Exec("update wukuard_network set cidr=?, cidr6=?, dns=?, listen_port=?, persistent_keepalive=?, updated_at=? where name=?",
+		record.CIDR, record.CIDR6, record.DNS, record.ListenPort, record.PersistentKeepalive, record.UpdatedAt, record.Name)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (s *mysqlStore) DeleteNetwork(name string) error {
+	result, err := s.db.Exec("delete from wukuard_network where name=?", name)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (s *mysqlStore) getPeer(where string, args ...interface{}) (*PeerRecord, error) {
+	rows, err := s.db.Query("select "+peerColumns+" from wukuard where "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	recordList := readPeerRecordList(rows)
+	if len(recordList) > 1 {
+		return nil, fmt.Errorf("duplicate peer: %s %v", where, args)
+	}
+	if len(recordList) <= 0 {
+		return nil, errNotFound
+	}
+	return recordList[0], nil
+}
+
+func (s *mysqlStore) GetPeer(id int32) (*PeerRecord, error) {
+	return s.getPeer("id = ?", id)
+}
+
+func (s *mysqlStore) GetPeerByToken(tokenHash string) (*PeerRecord, error) {
+	return s.getPeer("token = ?", tokenHash)
+}
+
+func (s *mysqlStore) GetPeerByHostname(network, hostname string) (*PeerRecord, error) {
+	return s.getPeer("network = ? and hostname = ?", network, hostname)
+}
+
+func (s *mysqlStore) ListPeers(network string) ([]*PeerRecord, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if network == "" {
+		rows, err = s.db.Query("select " + peerColumns + " from wukuard")
+	} else {
+		rows, err = s.db.Query("select "+peerColumns+" from wukuard where network = ?", network)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readPeerRecordList(rows), nil
+}
+
+func (s *mysqlStore) CreatePeer(record *PeerRecord) error {
+	result, err := s.db.Exec("insert into wukuard (network, mac_address, hostname, token, public_key, post_up, pre_down, address, "+
+		"listen_port, endpoint, allowed_ips, persistent_keepalive, tags, relay, relayed_by, routes, use_gateway, "+
+		"created_at, updated_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		record.Network, nullString(record.MacAddress), record.Hostname, nullString(record.Token), record.PublicKey, record.PostUp, record.PreDown, record.Address,
+		record.ListenPort, record.Endpoint, record.AllowedIPs, record.PersistentKeepalive, record.Tags, record.Relay, record.RelayedBy, record.Routes, record.UseGateway,
+		record.CreatedAt, record.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	record.ID = int32(id)
+	return nil
+}
+
+func (s *mysqlStore) UpdatePeer(record *PeerRecord) error {
+	result, err := s.db.Exec("update wukuard set network=?, mac_address=?, hostname=?, public_key=?, post_up=?, pre_down=?, address=?, "+
+		"listen_port=?, endpoint=?, allowed_ips=?, persistent_keepalive=?, tags=?, relay=?, relayed_by=?, routes=?, use_gateway=?, updated_at=? where id=?",
+		record.Network, nullString(record.MacAddress), record.Hostname, record.PublicKey, record.PostUp, record.PreDown, record.Address,
+		record.ListenPort, record.Endpoint, record.AllowedIPs, record.PersistentKeepalive, record.Tags, record.Relay, record.RelayedBy, record.Routes, record.UseGateway,
+		record.UpdatedAt, record.ID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (s *mysqlStore) DeletePeer(id int32) error {
+	record, err := s.GetPeer(id)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.Exec("delete from wukuard where id=?", id)
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(result); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("delete from wukuard_peer_stats where peer_id=?", id); err != nil {
+		return err
+	}
+	// the peers authenticated by their certificate alone have no credential hash
+	_, err = tx.Exec("insert into wukuard_deleted_peer (token_hash, network, hostname, deleted_at) values (?, ?, ?, ?)",
+		record.Token, record.Network, record.Hostname, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *mysqlStore) WasPeerDeleted(tokenHash string) (bool, error) {
+	var count int
+	err := s.db.QueryRow("select count(*) from wukuard_deleted_peer where token_hash = ?", tokenHash).Scan(&count)
+	return count > 0, err
+}
+
+func (s *mysqlStore) WasHostnameDeleted(network, hostname string) (bool, error) {
+	var count int
+	err := s.db.QueryRow("select count(*) from wukuard_deleted_peer where network = ? and hostname = ?", network, hostname).Scan(&count)
+	return count > 0, err
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// checkAffected returns errNotFound if no row is matched by the statement,
+// an update leaving a row as it was still matches it thanks to clientFoundRows
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected <= 0 {
+		return errNotFound
+	}
+	return nil
+}
+
+func (s *mysqlStore) UpdatePeerEndpoint(id int32, endpoint, publicEndpoint string) error {
+	_, err := s.db.Exec("update wukuard set endpoint=?, public_endpoint=? where id=?", endpoint, publicEndpoint, id)
+	return err
+}
+
+func (s *mysqlStore) UpdatePeerSeen(id int32, lastSeen int64, clientVersion, status string) error {
+	_, err := s.db.Exec("update wukuard set last_seen=?, client_version=?, status=? where id=?", lastSeen, clientVersion, status, id)
+	return err
+}
+
+func (s *mysqlStore) UpdatePeerStats(id int32, stats []*PeerStatsRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec("delete from wukuard_peer_stats where peer_id=?", id); err != nil {
+		return err
+	}
+	for _, record := range stats {
+		_, err = tx.Exec("insert into wukuard_peer_stats (peer_id, public_key, endpoint, last_handshake, rx_bytes, tx_bytes, reported_at) "+
+			"values (?, ?, ?, ?, ?, ?, ?)",
+			id, record.PublicKey, record.Endpoint, record.LastHandshake, record.RxBytes, record.TxBytes, record.ReportedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *mysqlStore) ListPeerStats(id int32) ([]*PeerStatsRecord, error) {
+	rows, err := s.db.Query("select peer_id, public_key, endpoint, last_handshake, rx_bytes, tx_bytes, reported_at "+
+		"from wukuard_peer_stats where peer_id=? order by public_key", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var stats []*PeerStatsRecord
+	for rows.Next() {
+		record := new(PeerStatsRecord)
+		err = rows.Scan(&record.PeerID, &record.PublicKey, &record.Endpoint, &record.LastHandshake,
+			&record.RxBytes, &record.TxBytes, &record.ReportedAt)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, record)
+	}
+	return stats, rows.Err()
+}
+
+func (s *mysqlStore) UpdatePeerPublicKey(id int32, publicKey string) error {
+	_, err := s.db.Exec("update wukuard set public_key=?, private_key='' where id=?", publicKey, id)
+	return err
+}
+
+func (s *mysqlStore) UpdatePeerToken(id int32, tokenHash, publicKey string) error {
+	_, err := s.db.Exec("update wukuard set token=?, public_key=?, private_key='' where id=?", tokenHash, publicKey, id)
+	return err
+}
+
+// policyColumns must be kept in the order readPolicyRecordList scans them
+const policyColumns = "id, network, description, from_tags, to_tags, cidrs, created_at, updated_at"
+
+func readPolicyRecordList(rows *sql.Rows) []*PolicyRecord {
+	var recordList []*PolicyRecord
+	defer rows.Close()
+	for rows.Next() {
+		record := new(PolicyRecord)
+		err := rows.Scan(&(record.ID), &(record.Network), &(record.Description), &(record.From), &(record.To),
+			&(record.CIDRs), &(record.CreatedAt), &(record.UpdatedAt))
+		if err != nil {
+			logger.error("read a policy from the DB", "err", err)
+			continue
+		}
+		recordList = append(recordList, record)
+	}
+	return recordList
+}
+
+func (s *mysqlStore) GetPolicy(id int32) (*PolicyRecord, error) {
+	rows, err := s.db.Query("select "+policyColumns+" from wukuard_policy where id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	recordList := readPolicyRecordList(rows)
+	if len(recordList) <= 0 {
+		return nil, errNotFound
+	}
+	return recordList[0], nil
+}
+
+func (s *mysqlStore) ListPolicies(network string) ([]*PolicyRecord, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if network == "" {
+		rows, err = s.db.Query("select " + policyColumns + " from wukuard_policy order by id")
+	} else {
+		rows, err = s.db.Query("select "+policyColumns+" from wukuard_policy where network = ? order by id", network)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readPolicyRecordList(rows), nil
+}
+
+func (s *mysqlStore) CreatePolicy(record *PolicyRecord) error {
+	result, err := s.db.Exec("insert into wukuard_policy (network, description, from_tags, to_tags, cidrs, created_at, updated_at) "+
+		"values (?, ?, ?, ?, ?, ?, ?)",
+		record.Network, record.Description, record.From, record.To, record.CIDRs, record.CreatedAt, record.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	record.ID = int32(id)
+	return nil
+}
+
+func (s *mysqlStore) UpdatePolicy(record *PolicyRecord) error {
+	result, err := s.db.Exec("update wukuard_policy set network=?, description=?, from_tags=?, to_tags=?, cidrs=?, updated_at=? where id=?",
+		record.Network, record.Description, record.From, record.To, record.CIDRs, record.UpdatedAt, record.ID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (s *mysqlStore) DeletePolicy(id int32) error {
+	result, err := s.db.Exec("delete from wukuard_policy where id=?", id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (s *mysqlStore) CreateJoinToken(token *JoinToken) error {
+	result, err := s.db.Exec("insert into wukuard_join_token (token_hash, network, hostname, expires_at, created_at) values (?, ?, ?, ?, ?)",
+		token.TokenHash, token.Network, token.Hostname, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	token.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *mysqlStore) ConsumeJoinToken(tokenHash string, now int64) (*JoinToken, error) {
+	token := &JoinToken{TokenHash: tokenHash}
+	row := s.db.QueryRow("select id, network, hostname, expires_at, created_at from wukuard_join_token where token_hash = ? and used_at is null", tokenHash)
+	if err := row.Scan(&token.ID, &token.Network, &token.Hostname, &token.ExpiresAt, &token.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	result, err := s.db.Exec("update wukuard_join_token set used_at = ? where id = ? and used_at is null", now, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		// consumed concurrently
+		return nil, errNotFound
+	}
+	token.UsedAt = now
+	return token, nil
+}
+
+func (s *mysqlStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// a minimal STUN client (RFC 5389), only sending Binding requests to learn the public address of the host
+const (
+	stunBindingRequest       = 0x0001
+	stunBindingSuccess       = 0x0101
+	stunMagicCookie          = 0x2112A442
+	stunHeaderSize           = 20
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXorMappedAddress = 0x0020
+	stunTimeout              = 3 * time.Second
+	// stunInterval is how long the client trusts the public address it learnt
+	stunInterval = 5 * time.Minute
+)
+
+var errNoMappedAddress = errors.New("no mapped address in the STUN response")
+
+func newStunRequest() (request []byte, transactionID []byte, err error) {
+	request = make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(request[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(request[2:], 0)
+	binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+	if _, err = rand.Read(request[8:stunHeaderSize]); err != nil {
+		return nil, nil, err
+	}
+	return request, request[8:stunHeaderSize], nil
+}
+
+// parseStunResponse returns the address mapped by the STUN server in the Binding response to transactionID
+func parseStunResponse(response, transactionID []byte) (*net.UDPAddr, error) {
+	if len(response) < stunHeaderSize {
+		return nil, errors.New("STUN response too short")
+	}
+	if binary.BigEndian.Uint16(response[0:]) != stunBindingSuccess {
+		return nil, fmt.Errorf("unexpected STUN message type %#04x", binary.BigEndian.Uint16(response[0:]))
+	}
+	if binary.BigEndian.Uint32(response[4:]) != stunMagicCookie || string(response[8:stunHeaderSize]) != string(transactionID) {
+		return nil, errors.New("STUN response to another request")
+	}
+	length := int(binary.BigEndian.Uint16(response[2:]))
+	if stunHeaderSize+length > len(response) {
+		return nil, errors.New("truncated STUN response")
+	}
+	var mapped *net.UDPAddr
+	attrs := response[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType, attrLength := binary.BigEndian.Uint16(attrs[0:]), int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLength > len(attrs) {
+			return nil, errors.New("truncated STUN attribute")
+		}
+		value := attrs[4 : 4+attrLength]
+		switch attrType {
+		case stunAttrXorMappedAddress:
+			// prefer it to MAPPED-ADDRESS, which some NATs rewrite
+			if addr := parseStunAddress(value, response[4:stunHeaderSize]); addr != nil {
+				return addr, nil
+			}
+		case stunAttrMappedAddress:
+			mapped = parseStunAddress(value, nil)
+		}
+		// attributes are padded to 4 bytes
+		attrs = attrs[4+(attrLength+3)&^3:]
+	}
+	if mapped == nil {
+		return nil, errNoMappedAddress
+	}
+	return mapped, nil
+}
+
+// parseStunAddress parses the value of a (XOR-)MAPPED-ADDRESS attribute, xored with xor unless nil
+func parseStunAddress(value, xor []byte) *net.UDPAddr {
+	if len(value) < 4 {
+		return nil
+	}
+	var size int
+	switch value[1] {
+	case 0x01:
+		size = net.IPv4len
+	case 0x02:
+		size = net.IPv6len
+	default:
+		return nil
+	}
+	if len(value) < 4+size {
+		return nil
+	}
+	port := binary.BigEndian.Uint16(value[2:])
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if xor != nil {
+		port ^= uint16(stunMagicCookie >> 16)
+		for i := range ip {
+			ip[i] ^= xor[i]
+		}
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
+
+// stunDiscover asks the STUN server at addr which public address the host is seen from,
+// returning the address mapped by the NAT along with the local address it was asked from.
+// The query can't go through the socket of WireGuard, which the kernel holds,
+// so the mapping of the listen port is only known to match if the NAT kept the local port here.
+func stunDiscover(addr string) (mapped, local *net.UDPAddr, err error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	request, transactionID, err := newStunRequest()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = conn.SetDeadline(time.Now().Add(stunTimeout)); err != nil {
+		return nil, nil, err
+	}
+	if _, err = conn.Write(request); err != nil {
+		return nil, nil, err
+	}
+	response := make([]byte, 1500)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, nil, err
+	}
+	mapped, err = parseStunResponse(response[:n], transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mapped, conn.LocalAddr().(*net.UDPAddr), nil
+}
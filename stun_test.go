@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+// stunAddressAttr encodes addr as a (XOR-)MAPPED-ADDRESS attribute of type attrType, xored with xor unless nil
+func stunAddressAttr(attrType uint16, addr *net.UDPAddr, xor []byte) []byte {
+	ip, family := addr.IP.To4(), byte(0x01)
+	if ip == nil {
+		ip, family = addr.IP.To16(), 0x02
+	}
+	value := make([]byte, 4+len(ip))
+	value[1] = family
+	port := uint16(addr.Port)
+	copy(value[4:], ip)
+	if xor != nil {
+		port ^= uint16(stunMagicCookie >> 16)
+		for i := range ip {
+			value[4+i] ^= xor[i]
+		}
+	}
+	binary.BigEndian.PutUint16(value[2:], port)
+	attr := make([]byte, 4, 4+len(value))
+	binary.BigEndian.PutUint16(attr[0:], attrType)
+	binary.BigEndian.PutUint16(attr[2:], uint16(len(value)))
+	return append(attr, value...)
+}
+
+// stunResponse builds the Binding success response to transactionID with attrs
+func stunResponse(transactionID []byte, attrs ...[]byte) []byte {
+	response := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(response[0:], stunBindingSuccess)
+	binary.BigEndian.PutUint32(response[4:], stunMagicCookie)
+	copy(response[8:], transactionID)
+	for _, attr := range attrs {
+		response = append(response, attr...)
+	}
+	binary.BigEndian.PutUint16(response[2:], uint16(len(response)-stunHeaderSize))
+	return response
+}
+
+func TestParseStunResponse(t *testing.T) {
+	_, transactionID, err := newStunRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the XOR-MAPPED-ADDRESS is xored with the magic cookie and the transaction id
+	xor := make([]byte, 16)
+	binary.BigEndian.PutUint32(xor, stunMagicCookie)
+	copy(xor[4:], transactionID)
+	public := &net.UDPAddr{IP: net.ParseIP("203.0.113.7").To4(), Port: 40001}
+	rewritten := &net.UDPAddr{IP: net.ParseIP("192.0.2.1").To4(), Port: 9619}
+	public6 := &net.UDPAddr{IP: net.ParseIP("2001:db8::7"), Port: 40001}
+
+	for _, test := range []struct {
+		name     string
+		response []byte
+		want     *net.UDPAddr
+	}{
+		{"xor mapped", stunResponse(transactionID, stunAddressAttr(stunAttrXorMappedAddress, public, xor)), public},
+		{"mapped", stunResponse(transactionID, stunAddressAttr(stunAttrMappedAddress, public, nil)), public},
+		{"xor preferred", stunResponse(transactionID,
+			stunAddressAttr(stunAttrMappedAddress, rewritten, nil),
+			stunAddressAttr(stunAttrXorMappedAddress, public, xor)), public},
+		{"ipv6", stunResponse(transactionID, stunAddressAttr(stunAttrXorMappedAddress, public6, xor)), public6},
+	} {
+		addr, err := parseStunResponse(test.response, transactionID)
+		if err != nil || !addr.IP.Equal(test.want.IP) || addr.Port != test.want.Port {
+			t.Errorf("%s: %v, %v, want %v", test.name, addr, err, test.want)
+		}
+	}
+
+	valid := stunResponse(transactionID, stunAddressAttr(stunAttrXorMappedAddress, public, xor))
+	otherID := make([]byte, len(transactionID))
+	for _, test := range []struct {
+		name          string
+		response      []byte
+		transactionID []byte
+	}{
+		{"no address", stunResponse(transactionID), transactionID},
+		{"other transaction", valid, otherID},
+		{"too short", valid[:stunHeaderSize-1], transactionID},
+		{"truncated", valid[:len(valid)-2], transactionID},
+		{"request", append([]byte{0, 1}, valid[2:]...), transactionID},
+	} {
+		if addr, err := parseStunResponse(test.response, test.transactionID); err == nil {
+			t.Errorf("%s: parsed %v", test.name, addr)
+		}
+	}
+}
+
+// serveStun answers the Binding requests sent to a local STUN server, as a public one would, and returns its address
+func serveStun(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	go func() {
+		request := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFrom(request)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderSize || binary.BigEndian.Uint16(request[0:]) != stunBindingRequest {
+				continue
+			}
+			transactionID := request[8:stunHeaderSize]
+			xor := make([]byte, 16)
+			binary.BigEndian.PutUint32(xor, stunMagicCookie)
+			copy(xor[4:], transactionID)
+			response := stunResponse(transactionID, stunAddressAttr(stunAttrXorMappedAddress, from.(*net.UDPAddr), xor))
+			_, _ = conn.WriteTo(response, from)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestStunDiscover(t *testing.T) {
+	mapped, local, err := stunDiscover(serveStun(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without NAT, the STUN server sees the local address
+	if !mapped.IP.Equal(net.ParseIP("127.0.0.1")) || mapped.Port != local.Port {
+		t.Errorf("mapped %v from %v", mapped, local)
+	}
+}
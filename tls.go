@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type ServerTLSConfig struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	// ClientCA enables mutual TLS, clients must present a certificate signed by it
+	ClientCA string `yaml:"clientCA"`
+}
+
+type ClientTLSConfig struct {
+	// CA is the only CA trusted to sign the server certificate
+	CA         string `yaml:"ca"`
+	Cert       string `yaml:"cert"`
+	Key        string `yaml:"key"`
+	ServerName string `yaml:"serverName"`
+}
+
+func loadCertPool(filename string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", filename)
+	}
+	return pool, nil
+}
+
+func (conf ServerTLSConfig) enabled() bool {
+	return conf.Cert != "" || conf.Key != ""
+}
+
+func (conf ServerTLSConfig) mutual() bool {
+	return conf.ClientCA != ""
+}
+
+func (conf ServerTLSConfig) tlsConfig() (*tls.Config, error) {
+	if conf.Cert == "" || conf.Key == "" {
+		return nil, errors.New("the server certificate needs both cert and key")
+	}
+	cert, err := tls.LoadX509KeyPair(conf.Cert, conf.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if conf.mutual() {
+		pool, err := loadCertPool(conf.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+func (conf ServerTLSConfig) serverOption() (grpc.ServerOption, error) {
+	tlsConfig, err := conf.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Creds(credentials.NewTLS(tlsConfig)), nil
+}
+
+// enabled is true as soon as any TLS setting is given, so that a partial config never falls back to plaintext
+func (conf ClientTLSConfig) enabled() bool {
+	return conf.CA != "" || conf.Cert != "" || conf.Key != "" || conf.ServerName != ""
+}
+
+func (conf ClientTLSConfig) tlsConfig() (*tls.Config, error) {
+	if (conf.Cert == "") != (conf.Key == "") {
+		return nil, errors.New("a client certificate needs both cert and key")
+	}
+	tlsConfig := &tls.Config{
+		ServerName: conf.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if conf.CA != "" {
+		pool, err := loadCertPool(conf.CA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if conf.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(conf.Cert, conf.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func (conf ClientTLSConfig) dialOption() (grpc.DialOption, error) {
+	if !conf.enabled() {
+		return grpc.WithInsecure(), nil
+	}
+	tlsConfig, err := conf.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// certIdentity returns the identity of the verified client certificate of the request,
+// which is the first DNS name of the certificate, or its common name if it has none.
+func certIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) <= 0 || len(tlsInfo.State.VerifiedChains[0]) <= 0 {
+		return "", false
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0], true
+	}
+	return cert.Subject.CommonName, cert.Subject.CommonName != ""
+}
+
+// checkCertIdentity makes sure that a client certificate, if any, belongs to hostname
+func checkCertIdentity(ctx context.Context, hostname string) error {
+	identity, ok := certIdentity(ctx)
+	if !ok || identity == hostname {
+		return nil
+	}
+	return fmt.Errorf("client certificate of %s does not match peer %s", identity, hostname)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+// testCA signs the certificates of a test, kept in dir
+type testCA struct {
+	t    *testing.T
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	// file is the PEM of the CA certificate
+	file string
+}
+
+var testSerial int64
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	ca := &testCA{t: t, dir: t.TempDir()}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	ca.cert, ca.key, ca.file = ca.issue(name, template, nil, nil)
+	return ca
+}
+
+// issue signs template with the CA, or self-signs it if parent is nil, and writes the certificate as <name>.crt
+func (ca *testCA) issue(name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string) {
+	ca.t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	testSerial++
+	template.SerialNumber = big.NewInt(testSerial)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	certFile := filepath.Join(ca.dir, name+".crt")
+	ca.writePEM(certFile, "CERTIFICATE", der)
+	return cert, key, certFile
+}
+
+func (ca *testCA) writePEM(filename, blockType string, der []byte) {
+	ca.t.Helper()
+	if err := os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		ca.t.Fatal(err)
+	}
+}
+
+// leaf issues a certificate for dnsName, usable by servers and clients, and returns its cert and key files
+func (ca *testCA) leaf(dnsName string) (string, string) {
+	ca.t.Helper()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsName},
+		DNSNames:    []string{dnsName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	_, key, certFile := ca.issue(dnsName, template, ca.cert, ca.key)
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	keyFile := filepath.Join(ca.dir, dnsName+".key")
+	ca.writePEM(keyFile, "EC PRIVATE KEY", der)
+	return certFile, keyFile
+}
+
+// serveTLS runs a gRPC server with conf, whose health checks report the certificate identity of the caller
+func serveTLS(t *testing.T, conf ServerTLSConfig) (string, <-chan string) {
+	t.Helper()
+	creds, err := conf.serverOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identities := make(chan string, 1)
+	s := grpc.NewServer(creds, grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity, _ := certIdentity(ctx)
+		identities <- identity
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), identities
+}
+
+// checkTLS calls the health service of addr with conf
+func checkTLS(addr string, conf ClientTLSConfig) error {
+	option, err := conf.dialOption()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr, option)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestTLS(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	cert, key := ca.leaf("wukuard.test")
+	addr, identities := serveTLS(t, ServerTLSConfig{Cert: cert, Key: key})
+
+	if err := checkTLS(addr, ClientTLSConfig{CA: ca.file, ServerName: "wukuard.test"}); err != nil {
+		t.Fatalf("trusted server: %v", err)
+	}
+	if identity := <-identities; identity != "" {
+		t.Errorf("identity without client certificate = %q", identity)
+	}
+
+	other := newTestCA(t, "other")
+	if err := checkTLS(addr, ClientTLSConfig{CA: other.file, ServerName: "wukuard.test"}); err == nil {
+		t.Error("server signed by an untrusted CA accepted")
+	}
+	if err := checkTLS(addr, ClientTLSConfig{CA: ca.file, ServerName: "other.test"}); err == nil {
+		t.Error("server with another name accepted")
+	}
+	if err := checkTLS(addr, ClientTLSConfig{}); err == nil {
+		t.Error("plaintext client accepted by a TLS server")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	serverCert, serverKey := ca.leaf("wukuard.test")
+	addr, identities := serveTLS(t, ServerTLSConfig{Cert: serverCert, Key: serverKey, ClientCA: ca.file})
+
+	cert, key := ca.leaf("node1")
+	if err := checkTLS(addr, ClientTLSConfig{CA: ca.file, ServerName: "wukuard.test", Cert: cert, Key: key}); err != nil {
+		t.Fatalf("client with a trusted certificate: %v", err)
+	}
+	if identity := <-identities; identity != "node1" {
+		t.Errorf("identity = %q, want node1", identity)
+	}
+
+	if err := checkTLS(addr, ClientTLSConfig{CA: ca.file, ServerName: "wukuard.test"}); err == nil {
+		t.Error("client without certificate accepted")
+	}
+	other := newTestCA(t, "other")
+	otherCert, otherKey := other.leaf("node1")
+	if err := checkTLS(addr, ClientTLSConfig{CA: ca.file, ServerName: "wukuard.test", Cert: otherCert, Key: otherKey}); err == nil {
+		t.Error("client certificate signed by an untrusted CA accepted")
+	}
+}
+
+func TestCheckCertIdentity(t *testing.T) {
+	if err := checkCertIdentity(context.Background(), "node1"); err != nil {
+		t.Errorf("call without certificate: %v", err)
+	}
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ignored"}, DNSNames: []string{"node1"}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+	if err := checkCertIdentity(ctx, "node1"); err != nil {
+		t.Errorf("matching certificate: %v", err)
+	}
+	if err := checkCertIdentity(ctx, "node2"); err == nil {
+		t.Error("certificate of node1 accepted for node2")
+	}
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	for _, conf := range []ClientTLSConfig{{CA: "ca.crt"}, {Cert: "c.crt"}, {Key: "c.key"}, {ServerName: "wukuard.test"}} {
+		if !conf.enabled() {
+			t.Errorf("%+v: TLS not enabled", conf)
+		}
+	}
+	if (ClientTLSConfig{}).enabled() {
+		t.Error("TLS enabled without any setting")
+	}
+	for _, conf := range []ClientTLSConfig{{Cert: "c.crt"}, {Key: "c.key"}} {
+		if _, err := conf.dialOption(); err == nil {
+			t.Errorf("%+v: half a client certificate accepted", conf)
+		}
+	}
+	if _, err := (ServerTLSConfig{Cert: "s.crt"}).serverOption(); err == nil {
+		t.Error("server certificate without key accepted")
+	}
+}
@@ -1,15 +1,6 @@
 package main
 
-import (
-	"log"
-	"os"
-)
-
-func checkErr(err error) {
-	if err != nil {
-		log.Printf("ERROR: %s", err.Error())
-	}
-}
+import "os"
 
 func readFile(filename string) (string, error) {
 	bytes, err := os.ReadFile(filename)
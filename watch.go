@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/loheagn/wukuard/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// resyncInterval is how often a watched network is rebuilt anyway,
+// to catch the changes made to the store behind the back of the server
+const resyncInterval = 30 * time.Second
+
+// broadcaster wakes up all the watchers whenever the network changes
+type broadcaster struct {
+	mu      sync.Mutex
+	version int64
+	changed chan struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	// versions keep increasing across restarts
+	return &broadcaster{version: time.Now().UnixNano(), changed: make(chan struct{})}
+}
+
+// current returns the current version and a channel closed on the next change
+func (b *broadcaster) current() (int64, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.version, b.changed
+}
+
+func (b *broadcaster) notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.version++
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// notifyingStore notifies the broadcaster after every successful change of the networks or the peers
+type notifyingStore struct {
+	PeerStore
+	broadcaster *broadcaster
+}
+
+func (s *notifyingStore) notify(err error) error {
+	if err == nil {
+		s.broadcaster.notify()
+	}
+	return err
+}
+
+func (s *notifyingStore) CreateNetwork(record *NetworkRecord) error {
+	return s.notify(s.PeerStore.CreateNetwork(record))
+}
+
+func (s *notifyingStore) UpdateNetwork(record *NetworkRecord) error {
+	return s.notify(s.PeerStore.UpdateNetwork(record))
+}
+
+func (s *notifyingStore) DeleteNetwork(name string) error {
+	return s.notify(s.PeerStore.DeleteNetwork(name))
+}
+
+func (s *notifyingStore) CreatePeer(record *PeerRecord) error {
+	return s.notify(s.PeerStore.CreatePeer(record))
+}
+
+func (s *notifyingStore) UpdatePeer(record *PeerRecord) error {
+	return s.notify(s.PeerStore.UpdatePeer(record))
+}
+
+func (s *notifyingStore) DeletePeer(id int32) error {
+	return s.notify(s.PeerStore.DeletePeer(id))
+}
+
+func (s *notifyingStore) UpdatePeerEndpoint(id int32, endpoint, publicEndpoint string) error {
+	return s.notify(s.PeerStore.UpdatePeerEndpoint(id, endpoint, publicEndpoint))
+}
+
+func (s *notifyingStore) UpdatePeerPublicKey(id int32, publicKey string) error {
+	return s.notify(s.PeerStore.UpdatePeerPublicKey(id, publicKey))
+}
+
+func (s *notifyingStore) UpdatePeerToken(id int32, tokenHash, publicKey string) error {
+	return s.notify(s.PeerStore.UpdatePeerToken(id, tokenHash, publicKey))
+}
+
+func (s *notifyingStore) CreatePolicy(record *PolicyRecord) error {
+	return s.notify(s.PeerStore.CreatePolicy(record))
+}
+
+func (s *notifyingStore) UpdatePolicy(record *PolicyRecord) error {
+	return s.notify(s.PeerStore.UpdatePolicy(record))
+}
+
+func (s *notifyingStore) DeletePolicy(id int32) error {
+	return s.notify(s.PeerStore.DeletePolicy(id))
+}
+
+func (s *server) WatchNetwork(req *pb.PeerRequest, stream pb.SyncNet_WatchNetworkServer) error {
+	ctx := stream.Context()
+	if _, err := s.checkIn(ctx, req); err != nil {
+		if err == errPeerDeleted {
+			return stream.Send(&pb.NetWorkResponse{Deleted: true})
+		}
+		return err
+	}
+	watchers.Inc()
+	defer watchers.Dec()
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+
+	var last *pb.NetWorkResponse
+	for {
+		version, changed := s.broadcaster.current()
+		self, err := s.authenticate(ctx, req.Network, req.Credential)
+		if err == errPeerDeleted {
+			loggerFrom(ctx).info("close the network stream of a deleted peer", "client_hostname", req.Hostname)
+			return stream.Send(&pb.NetWorkResponse{Deleted: true})
+		}
+		if err != nil {
+			return err
+		}
+		if time.Since(time.Unix(self.LastSeen, 0)) >= resyncInterval/2 {
+			// the open stream tells that the client is alive, even if it doesn't heartbeat
+			s.markSeen(self, self.ClientVersion, self.Status)
+		}
+		resp, err := s.buildNetwork(ctx, self)
+		if err != nil {
+			return err
+		}
+		if last == nil || !proto.Equal(resp, last) {
+			last = resp
+			sent := proto.Clone(resp).(*pb.NetWorkResponse)
+			sent.Version = version
+			if err = stream.Send(sent); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// WireGuardBackend reads and configures the WireGuard interface
+type WireGuardBackend interface {
+	// Current returns the actual configuration of the interface, nil if it doesn't exist
+	Current() (*WgConf, error)
+	// Apply executes the operations of plan, computed against the result of Current
+	Apply(plan *Plan) error
+	// Down removes the interface
+	Down() error
+	// Stats returns the state of the tunnel to every peer, none if the interface doesn't exist
+	Stats() ([]*PeerStats, error)
+}
+
+// PeerStats is the state of the tunnel to a peer, as seen by the device
+type PeerStats struct {
+	PublicKey string
+	// Endpoint is the current one, which may have roamed
+	Endpoint string
+	// LastHandshake is zero if no handshake happened yet
+	LastHandshake time.Time
+	RxBytes       int64
+	TxBytes       int64
+}
+
+const (
+	backendNetlink = "netlink"
+	backendWgQuick = "wg-quick"
+)
+
+func newWireGuardBackend(conf *ClientConfig) (WireGuardBackend, error) {
+	switch conf.Backend {
+	case "", backendNetlink:
+		return newNetlinkBackend(conf.Interface)
+	case backendWgQuick:
+		return &wgQuickBackend{
+			name:         conf.Interface,
+			log:          logger.with("interface", conf.Interface),
+			confFilename: conf.confFilename(),
+			serviceName:  conf.serviceName(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown WireGuard backend: %s", conf.Backend)
+	}
+}
+
+// wgQuickBackend writes the configuration file of wg-quick and restarts its service on every change,
+// which drops all the tunnels for a while
+type wgQuickBackend struct {
+	name         string
+	log          *fieldLogger
+	confFilename string
+	serviceName  string
+}
+
+func (b *wgQuickBackend) getCurrentConf() (string, error) {
+	wholeConfStr, err := readFile(b.confFilename)
+	if err != nil {
+		return "", err
+	}
+	return wholeConfStr, nil
+}
+
+func (b *wgQuickBackend) checkServiceIsRunning() bool {
+	output, err := exec.Command("systemctl", "status", b.serviceName).Output()
+	if err != nil {
+		return false
+	}
+	matched, err := regexp.MatchString("Active: active", string(output))
+	return err == nil && matched
+}
+
+func (b *wgQuickBackend) prepareConfFile() error {
+	syscall.Umask(0022)
+	basePath := filepath.Dir(b.confFilename)
+	if _, err := os.Stat(basePath); os.IsNotExist(err) {
+		err = os.MkdirAll(basePath, os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+	// generate the wg conf
+	if _, err := os.Stat(b.confFilename); os.IsNotExist(err) {
+		confFile, err := os.Create(b.confFilename)
+		if err != nil {
+			return err
+		}
+		_ = confFile.Close()
+	} else {
+		return err
+	}
+	return nil
+}
+
+// parseWgConf parses the configuration file of wg-quick, as generated by WgConf.generateString
+func parseWgConf(content string) *WgConf {
+	wgConf := &WgConf{}
+	var peerConf *PeerConf
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "[Interface]":
+			wgConf.interfaceConf, peerConf = &InterfaceConf{}, nil
+			continue
+		case line == "[Peer]":
+			peerConf = &PeerConf{}
+			wgConf.peerConfList = append(wgConf.peerConfList, peerConf)
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := strings.ToLower(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+		if peerConf != nil {
+			switch key {
+			case "publickey":
+				peerConf.PublicKey = value
+			case "allowedips":
+				peerConf.AllowedIPs = value
+			case "endpoint":
+				peerConf.Endpoint = value
+			case "persistentkeepalive":
+				keepalive, _ := strconv.Atoi(value)
+				peerConf.PersistentKeepalive = int32(keepalive)
+			}
+		} else if wgConf.interfaceConf != nil {
+			switch key {
+			case "privatekey":
+				wgConf.interfaceConf.PrivateKey = value
+			case "address":
+				wgConf.interfaceConf.Address = value
+			case "listenport":
+				listenPort, _ := strconv.Atoi(value)
+				wgConf.interfaceConf.ListenPort = int32(listenPort)
+			case "postup":
+				wgConf.interfaceConf.PostUp = value
+			case "predown":
+				wgConf.interfaceConf.PreDown = value
+			case "dns":
+				wgConf.interfaceConf.DNS = value
+			}
+		}
+	}
+	return wgConf
+}
+
+// Current reads the configuration file, the interface only exists if the service is running
+func (b *wgQuickBackend) Current() (*WgConf, error) {
+	if !b.checkServiceIsRunning() {
+		return nil, nil
+	}
+	wholeConfStr, err := b.getCurrentConf()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseWgConf(wholeConfStr), nil
+}
+
+// Apply can't configure single peers, it rewrites the whole file and restarts the service
+func (b *wgQuickBackend) Apply(plan *Plan) error {
+	err := b.prepareConfFile()
+	if err != nil {
+		return err
+	}
+	if err = writeFile(b.confFilename, plan.desired.generateString()); err != nil {
+		return err
+	}
+	b.log.info("restart the wg-quick service", "service", b.serviceName)
+	clientRestartsTotal.WithLabelValues(b.name).Inc()
+	err = exec.Command("systemctl", "restart", b.serviceName).Run()
+	if err != nil {
+		// stupid but effective
+		_ = exec.Command("ip", "link", "delete", b.name).Run()
+		clientRestartsTotal.WithLabelValues(b.name).Inc()
+		return exec.Command("systemctl", "restart", b.serviceName).Run()
+	}
+	return nil
+}
+
+func (b *wgQuickBackend) Down() error {
+	if err := writeFile(b.confFilename, ""); err != nil {
+		b.log.error("clear the config of wg-quick", "file", b.confFilename, "err", err)
+	}
+	if b.checkServiceIsRunning() {
+		b.log.info("stop the wg-quick service", "service", b.serviceName)
+		_ = exec.Command("ip", "link", "delete", b.name).Run()
+		return exec.Command("systemctl", "stop", b.serviceName).Run()
+	}
+	return nil
+}
+
+// Stats parses the output of wg show dump:
+// a line for the interface, then one per peer with its public key, preshared key, endpoint, AllowedIPs,
+// latest handshake, received and sent bytes and PersistentKeepalive, separated by tabs
+func (b *wgQuickBackend) Stats() ([]*PeerStats, error) {
+	if _, err := net.InterfaceByName(b.name); err != nil {
+		return nil, nil
+	}
+	output, err := exec.Command("wg", "show", b.name, "dump").Output()
+	if err != nil {
+		return nil, fmt.Errorf("wg show %s dump: %w", b.name, err)
+	}
+	var stats []*PeerStats
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 8 {
+			continue
+		}
+		peerStats := &PeerStats{PublicKey: fields[0]}
+		if fields[2] != "(none)" {
+			peerStats.Endpoint = fields[2]
+		}
+		if handshake, _ := strconv.ParseInt(fields[4], 10, 64); handshake > 0 {
+			peerStats.LastHandshake = time.Unix(handshake, 0)
+		}
+		peerStats.RxBytes, _ = strconv.ParseInt(fields[5], 10, 64)
+		peerStats.TxBytes, _ = strconv.ParseInt(fields[6], 10, 64)
+		stats = append(stats, peerStats)
+	}
+	return stats, nil
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// netlinkBackend configures the interface in place through wgctrl and iproute2,
+// only touching the peers, addresses and routes which changed, so the other tunnels stay up
+type netlinkBackend struct {
+	name   string
+	log    *fieldLogger
+	client wgClient
+	// routes are the routes installed for AllowedIPs out of the subnets of the interface
+	routes map[string]bool
+	// defaultRoutes are the families, "-4" or "-6", whose default route goes through the interface,
+	// with the packets of the tunnel marked by fwmark
+	defaultRoutes map[string]bool
+	fwmark        int
+	// hooksApplied is true once the hooks of the interface are known to have run
+	hooksApplied bool
+	// the hooks and DNS servers of the last applied configuration, which the device doesn't know about
+	postUp, preDown, dns string
+	// endpoints are the configured endpoints of the peers, by public key.
+	// They are reported by Current instead of the roaming endpoints of the device,
+	// so that the roaming isn't undone by the next reconciliation.
+	endpoints map[string]string
+}
+
+func newNetlinkBackend(name string) (*netlinkBackend, error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return nil, err
+	}
+	return &netlinkBackend{
+		name:          name,
+		log:           logger.with("interface", name),
+		client:        client,
+		routes:        make(map[string]bool),
+		defaultRoutes: make(map[string]bool),
+		endpoints:     make(map[string]string),
+	}, nil
+}
+
+// wgClient configures the WireGuard devices, as wgctrl.Client does
+type wgClient interface {
+	Device(name string) (*wgtypes.Device, error)
+	ConfigureDevice(name string, cfg wgtypes.Config) error
+	Close() error
+}
+
+// Close releases the netlink socket, the interface stays as it is
+func (b *netlinkBackend) Close() error {
+	return b.client.Close()
+}
+
+// runIP and linkAddrs are variables for the tests to stand in for the system
+var (
+	runIP = func(args ...string) error {
+		output, err := exec.Command("ip", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("ip %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	// linkAddrs returns the addresses of the link name, failing if there is no such link
+	linkAddrs = func(name string) ([]net.Addr, error) {
+		link, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, err
+		}
+		return link.Addrs()
+	}
+)
+
+// runHook runs PostUp or PreDown like wg-quick does, %i being the interface name
+func (b *netlinkBackend) runHook(hook string) {
+	if hook == "" {
+		return
+	}
+	hook = strings.ReplaceAll(hook, "%i", b.name)
+	if output, err := exec.Command("/bin/bash", "-c", hook).CombinedOutput(); err != nil {
+		b.log.error("run hook", "hook", hook, "err", err, "output", strings.TrimSpace(string(output)))
+	}
+}
+
+// configureDNS registers the DNS servers of the interface to resolvconf like wg-quick does,
+// or removes them if dns is empty
+func (b *netlinkBackend) configureDNS(dns string) {
+	var cmd *exec.Cmd
+	if dns == "" {
+		cmd = exec.Command("resolvconf", "-d", "tun."+b.name, "-f")
+	} else {
+		var lines []string
+		for _, server := range splitList(dns) {
+			lines = append(lines, "nameserver "+server)
+		}
+		cmd = exec.Command("resolvconf", "-a", "tun."+b.name, "-m", "0", "-x")
+		cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		b.log.error("set the DNS servers", "err", err, "output", strings.TrimSpace(string(output)))
+	}
+}
+
+func (b *netlinkBackend) linkExists() bool {
+	_, err := linkAddrs(b.name)
+	return err == nil
+}
+
+func parseKey(key string) (wgtypes.Key, error) {
+	return wgtypes.ParseKey(strings.TrimSpace(key))
+}
+
+func buildPeerConfig(peer *PeerConf) (wgtypes.PeerConfig, error) {
+	publicKey, err := parseKey(peer.PublicKey)
+	if err != nil {
+		return wgtypes.PeerConfig{}, err
+	}
+	config := wgtypes.PeerConfig{
+		PublicKey:         publicKey,
+		ReplaceAllowedIPs: true,
+	}
+	if peer.Endpoint != "" {
+		if config.Endpoint, err = net.ResolveUDPAddr("udp", peer.Endpoint); err != nil {
+			return config, err
+		}
+	}
+	keepalive := time.Duration(peer.PersistentKeepalive) * time.Second
+	config.PersistentKeepaliveInterval = &keepalive
+	allowedIPs, err := parseCIDRList(peer.AllowedIPs)
+	if err != nil {
+		return config, err
+	}
+	for _, allowed := range allowedIPs {
+		config.AllowedIPs = append(config.AllowedIPs, net.IPNet{IP: allowed.IP.Mask(allowed.Mask), Mask: allowed.Mask})
+	}
+	return config, nil
+}
+
+// configureDevice sets the keys and the port if they changed, and adds, updates or removes the peers of plan
+func (b *netlinkBackend) configureDevice(plan *Plan) error {
+	config := wgtypes.Config{}
+	interfaceConf := plan.desired.interfaceConf
+	if plan.interfaceChanged("private key") {
+		privateKey, err := parseKey(interfaceConf.PrivateKey)
+		if err != nil {
+			return err
+		}
+		config.PrivateKey = &privateKey
+	}
+	if plan.interfaceChanged("listen port") {
+		listenPort := int(interfaceConf.ListenPort)
+		config.ListenPort = &listenPort
+	}
+
+	peers := plan.addPeers
+	for _, change := range plan.updatePeers {
+		peers = append(peers, change.peer)
+	}
+	for _, peer := range peers {
+		peerConfig, err := buildPeerConfig(peer)
+		if err != nil {
+			b.log.error("skip peer", "public_key", peer.PublicKey, "err", err)
+			continue
+		}
+		config.Peers = append(config.Peers, peerConfig)
+		b.endpoints[peer.PublicKey] = peer.Endpoint
+	}
+	for _, peer := range plan.removePeers {
+		publicKey, err := parseKey(peer.PublicKey)
+		if err != nil {
+			continue
+		}
+		config.Peers = append(config.Peers, wgtypes.PeerConfig{PublicKey: publicKey, Remove: true})
+		delete(b.endpoints, peer.PublicKey)
+	}
+	if config.PrivateKey == nil && config.ListenPort == nil && len(config.Peers) <= 0 {
+		return nil
+	}
+	return b.client.ConfigureDevice(b.name, config)
+}
+
+// addresses returns the addresses of the interface, with their host part
+func (b *netlinkBackend) addresses() ([]*net.IPNet, error) {
+	addrs, err := linkAddrs(b.name)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []*net.IPNet
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			addresses = append(addresses, ipNet)
+		}
+	}
+	return addresses, nil
+}
+
+// configureAddresses adds the missing addresses of the interface and removes the others
+func (b *netlinkBackend) configureAddresses(address string) error {
+	wanted, err := parseCIDRList(address)
+	if err != nil {
+		return err
+	}
+	addresses, err := b.addresses()
+	if err != nil {
+		return err
+	}
+	current := make(map[string]bool)
+	for _, ipNet := range addresses {
+		current[ipNet.String()] = true
+	}
+	for _, ipNet := range wanted {
+		if current[ipNet.String()] {
+			delete(current, ipNet.String())
+			continue
+		}
+		if err = runIP("address", "add", ipNet.String(), "dev", b.name); err != nil {
+			return err
+		}
+	}
+	for addr := range current {
+		if err = runIP("address", "del", addr, "dev", b.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureRoutes routes the AllowedIPs which are not covered by the subnets of the interface to it
+func (b *netlinkBackend) configureRoutes(conf *WgConf, addresses []*net.IPNet) error {
+	wanted := make(map[string]bool)
+	var allAllowedIPs []*net.IPNet
+	for _, peer := range conf.peerConfList {
+		allowedIPs, _ := parseCIDRList(peer.AllowedIPs)
+		allAllowedIPs = append(allAllowedIPs, allowedIPs...)
+	next:
+		for _, allowed := range allowedIPs {
+			if isDefaultRoute(allowed) {
+				continue
+			}
+			ones, _ := allowed.Mask.Size()
+			for _, address := range addresses {
+				addressOnes, _ := address.Mask.Size()
+				if address.Contains(allowed.IP) && addressOnes <= ones {
+					continue next
+				}
+			}
+			wanted[(&net.IPNet{IP: allowed.IP.Mask(allowed.Mask), Mask: allowed.Mask}).String()] = true
+		}
+	}
+	for route := range wanted {
+		if b.routes[route] {
+			continue
+		}
+		if err := runIP("route", "replace", route, "dev", b.name); err != nil {
+			return err
+		}
+		b.routes[route] = true
+	}
+	for route := range b.routes {
+		if wanted[route] {
+			continue
+		}
+		if err := runIP("route", "del", route, "dev", b.name); err != nil {
+			b.log.error("remove route", "route", route, "err", err)
+		}
+		delete(b.routes, route)
+	}
+	return b.configureDefaultRoutes(defaultRouteFamilies(allAllowedIPs), int(conf.interfaceConf.ListenPort))
+}
+
+// configureDefaultRoutes sends the traffic of families to the interface like wg-quick does:
+// the packets of the tunnel are marked and keep the main table, every other packet goes to the table fwmark
+// whose default route is the interface, unless the main table has a more specific route for it.
+func (b *netlinkBackend) configureDefaultRoutes(families map[string]bool, fwmark int) error {
+	if fwmark == 0 {
+		fwmark = defaultListenPort
+	}
+	if len(families) > 0 && fwmark != b.fwmark {
+		for family := range b.defaultRoutes {
+			b.removeDefaultRoute(family)
+		}
+		if err := b.client.ConfigureDevice(b.name, wgtypes.Config{FirewallMark: &fwmark}); err != nil {
+			return err
+		}
+		b.fwmark = fwmark
+	}
+	table := fmt.Sprint(b.fwmark)
+	for family := range families {
+		if b.defaultRoutes[family] {
+			continue
+		}
+		b.log.info("route the default traffic through the interface", "family", family)
+		// the rules may be left over by a previous run
+		b.removeDefaultRoute(family)
+		if err := runIP(family, "route", "replace", "default", "dev", b.name, "table", table); err != nil {
+			return err
+		}
+		if err := runIP(family, "rule", "add", "not", "fwmark", table, "table", table); err != nil {
+			return err
+		}
+		if err := addSuppressRule(family); err != nil {
+			return err
+		}
+		b.defaultRoutes[family] = true
+	}
+	for family := range b.defaultRoutes {
+		if !families[family] {
+			b.log.info("stop routing the default traffic through the interface", "family", family)
+			b.removeDefaultRoute(family)
+		}
+	}
+	return nil
+}
+
+// removeDefaultRoute removes the rules and the route of the default route of family through the interface
+func (b *netlinkBackend) removeDefaultRoute(family string) {
+	table := fmt.Sprint(b.fwmark)
+	// the errors are expected when there is nothing to remove
+	_ = runIP(family, "rule", "del", "not", "fwmark", table, "table", table)
+	_ = runIP(family, "route", "del", "default", "dev", b.name, "table", table)
+	removeSuppressRule(family)
+	delete(b.defaultRoutes, family)
+}
+
+// The rule letting the more specific routes of the main table win over the default routes of the interfaces
+// is shared by all the interfaces routing the default traffic, those of the other agents and of wg-quick included.
+const suppressRule = "lookup main suppress_prefixlength 0"
+
+// ipRules lists the rules of family as ip prints them
+func ipRules(family string) (string, error) {
+	output, err := exec.Command("ip", family, "rule", "show").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("ip %s rule show: %w: %s", family, err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// addSuppressRule adds the shared rule of family unless another interface already did
+func addSuppressRule(family string) error {
+	rules, err := ipRules(family)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(rules, suppressRule) {
+		return nil
+	}
+	return runIP(family, "rule", "add", "table", "main", "suppress_prefixlength", "0")
+}
+
+// removeSuppressRule removes the shared rule of family once no interface routes the default traffic anymore
+func removeSuppressRule(family string) {
+	rules, err := ipRules(family)
+	if err != nil || routesDefaultTraffic(rules) {
+		return
+	}
+	_ = runIP(family, "rule", "del", "table", "main", "suppress_prefixlength", "0")
+}
+
+// routesDefaultTraffic tells whether rules still send the unmarked packets to the table of an interface,
+// as the rule "not from all fwmark 0x259b lookup 9627" does
+func routesDefaultTraffic(rules string) bool {
+	return strings.Contains(rules, "not from all fwmark")
+}
+
+func (b *netlinkBackend) Current() (*WgConf, error) {
+	if !b.linkExists() {
+		return nil, nil
+	}
+	device, err := b.client.Device(b.name)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := b.addresses()
+	if err != nil {
+		return nil, err
+	}
+	var addressList []string
+	for _, address := range addresses {
+		addressList = append(addressList, address.String())
+	}
+	wgConf := &WgConf{
+		interfaceConf: &InterfaceConf{
+			PrivateKey: device.PrivateKey.String(),
+			Address:    strings.Join(addressList, ", "),
+			ListenPort: int32(device.ListenPort),
+			PostUp:     b.postUp,
+			PreDown:    b.preDown,
+			DNS:        b.dns,
+		},
+	}
+	for _, peer := range device.Peers {
+		publicKey := peer.PublicKey.String()
+		var allowedIPs []string
+		for _, allowed := range peer.AllowedIPs {
+			allowedIPs = append(allowedIPs, allowed.String())
+		}
+		endpoint, ok := b.endpoints[publicKey]
+		if !ok && peer.Endpoint != nil {
+			endpoint = peer.Endpoint.String()
+		}
+		wgConf.peerConfList = append(wgConf.peerConfList, &PeerConf{
+			PublicKey:           publicKey,
+			AllowedIPs:          strings.Join(allowedIPs, ", "),
+			Endpoint:            endpoint,
+			PersistentKeepalive: int32(peer.PersistentKeepaliveInterval / time.Second),
+		})
+	}
+	return wgConf, nil
+}
+
+func (b *netlinkBackend) Stats() ([]*PeerStats, error) {
+	if !b.linkExists() {
+		return nil, nil
+	}
+	device, err := b.client.Device(b.name)
+	if err != nil {
+		return nil, err
+	}
+	var stats []*PeerStats
+	for _, peer := range device.Peers {
+		peerStats := &PeerStats{
+			PublicKey: peer.PublicKey.String(),
+			RxBytes:   peer.ReceiveBytes,
+			TxBytes:   peer.TransmitBytes,
+		}
+		if peer.Endpoint != nil {
+			peerStats.Endpoint = peer.Endpoint.String()
+		}
+		if !peer.LastHandshakeTime.IsZero() && peer.LastHandshakeTime.Unix() > 0 {
+			peerStats.LastHandshake = peer.LastHandshakeTime
+		}
+		stats = append(stats, peerStats)
+	}
+	return stats, nil
+}
+
+func (b *netlinkBackend) Apply(plan *Plan) (err error) {
+	interfaceConf := plan.desired.interfaceConf
+	if plan.createInterface {
+		b.log.info("create interface")
+		if err = runIP("link", "add", "dev", b.name, "type", "wireguard"); err != nil {
+			return err
+		}
+		b.routes = make(map[string]bool)
+		b.endpoints = make(map[string]string)
+		b.fwmark = 0
+		defer func() {
+			if err != nil {
+				b.removeFailedLink()
+			}
+		}()
+	}
+	if err = b.configureDevice(plan); err != nil {
+		return err
+	}
+	if plan.interfaceChanged("address") {
+		if err = b.configureAddresses(interfaceConf.Address); err != nil {
+			return err
+		}
+	}
+	if plan.createInterface {
+		if err = runIP("link", "set", "up", "dev", b.name); err != nil {
+			return err
+		}
+	}
+	addresses, err := b.addresses()
+	if err != nil {
+		return err
+	}
+	if err = b.configureRoutes(plan.desired, addresses); err != nil {
+		return err
+	}
+	if interfaceConf.DNS != b.dns || (plan.createInterface && interfaceConf.DNS != "") {
+		b.configureDNS(interfaceConf.DNS)
+	}
+	if plan.createInterface {
+		b.runHook(interfaceConf.PostUp)
+	} else if b.hooksApplied && (plan.interfaceChanged("PostUp") || plan.interfaceChanged("PreDown")) {
+		// undo what the previous hooks did before running the new ones, as recreating the interface would
+		b.runHook(b.preDown)
+		b.runHook(interfaceConf.PostUp)
+	}
+	b.postUp, b.preDown, b.dns = interfaceConf.PostUp, interfaceConf.PreDown, interfaceConf.DNS
+	b.hooksApplied = true
+	return nil
+}
+
+// removeFailedLink deletes the link whose creation failed partway.
+// The next plan then creates it again, whereas a link left down would never be brought up nor get its PostUp.
+func (b *netlinkBackend) removeFailedLink() {
+	b.log.warn("remove the interface left half configured")
+	for family := range b.defaultRoutes {
+		b.removeDefaultRoute(family)
+	}
+	b.routes = make(map[string]bool)
+	b.endpoints = make(map[string]string)
+	if err := runIP("link", "delete", "dev", b.name); err != nil {
+		b.log.error("remove interface", "err", err)
+	}
+}
+
+func (b *netlinkBackend) Down() error {
+	if !b.linkExists() {
+		return nil
+	}
+	b.log.info("remove interface")
+	b.runHook(b.preDown)
+	if b.dns != "" {
+		b.configureDNS("")
+		b.dns = ""
+	}
+	for family := range b.defaultRoutes {
+		b.removeDefaultRoute(family)
+	}
+	b.routes = make(map[string]bool)
+	b.hooksApplied = false
+	return runIP("link", "delete", "dev", b.name)
+}
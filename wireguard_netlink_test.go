@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+func TestSharedSuppressRule(t *testing.T) {
+	// ip -4 rule show with wukuard and lab routing the default traffic
+	rules := `0:	from all lookup local
+32763:	not from all fwmark 0x259c lookup 9628
+32764:	from all lookup main suppress_prefixlength 0
+32765:	not from all fwmark 0x259b lookup 9627
+32766:	from all lookup main
+32767:	from all lookup default
+`
+	if !strings.Contains(rules, suppressRule) {
+		t.Error("suppress rule not found")
+	}
+	if !routesDefaultTraffic(rules) {
+		t.Error("the rule of lab would be removed")
+	}
+	// once lab stopped
+	rules = `0:	from all lookup local
+32764:	from all lookup main suppress_prefixlength 0
+32766:	from all lookup main
+32767:	from all lookup default
+`
+	if routesDefaultTraffic(rules) {
+		t.Error("the rule would be kept without interface routing the default traffic")
+	}
+}
+
+// fakeLinks stands in for ip and wgctrl, failing the ip commands starting with failing
+type fakeLinks struct {
+	links   map[string][]net.Addr
+	up      map[string]bool
+	failing string
+}
+
+func useFakeLinks(t *testing.T) *fakeLinks {
+	f := &fakeLinks{links: make(map[string][]net.Addr), up: make(map[string]bool)}
+	savedRunIP, savedLinkAddrs := runIP, linkAddrs
+	t.Cleanup(func() {
+		runIP, linkAddrs = savedRunIP, savedLinkAddrs
+	})
+	runIP, linkAddrs = f.runIP, f.linkAddrs
+	return f
+}
+
+func (f *fakeLinks) runIP(args ...string) error {
+	command := strings.Join(args, " ")
+	if f.failing != "" && strings.HasPrefix(command, f.failing) {
+		return errors.New("ip " + command + ": failed")
+	}
+	name := args[len(args)-1]
+	switch {
+	case strings.HasPrefix(command, "link add dev "):
+		name = args[3]
+		f.links[name] = nil
+	case strings.HasPrefix(command, "link delete "):
+		delete(f.links, name)
+		delete(f.up, name)
+	case strings.HasPrefix(command, "link set up "):
+		f.up[name] = true
+	case strings.HasPrefix(command, "address add "):
+		ip, ipNet, _ := net.ParseCIDR(args[2])
+		f.links[name] = append(f.links[name], &net.IPNet{IP: ip, Mask: ipNet.Mask})
+	}
+	return nil
+}
+
+func (f *fakeLinks) linkAddrs(name string) ([]net.Addr, error) {
+	addrs, ok := f.links[name]
+	if !ok {
+		return nil, errors.New("no such network interface")
+	}
+	return addrs, nil
+}
+
+func (f *fakeLinks) Device(name string) (*wgtypes.Device, error) {
+	return &wgtypes.Device{Name: name}, nil
+}
+
+func (f *fakeLinks) ConfigureDevice(string, wgtypes.Config) error {
+	return nil
+}
+
+func (f *fakeLinks) Close() error {
+	return nil
+}
+
+func TestNetlinkApplyFailedCreation(t *testing.T) {
+	f := useFakeLinks(t)
+	b := &netlinkBackend{name: "wk0", log: logger, client: f, defaultRoutes: make(map[string]bool)}
+	privateKey, err := generatePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := &WgConf{interfaceConf: &InterfaceConf{PrivateKey: privateKey, Address: "10.0.0.1/24", ListenPort: 9619}}
+
+	f.failing = "address add"
+	if err = b.Apply(diffWgConf(desired, nil)); err == nil {
+		t.Fatal("apply succeeded without address")
+	}
+	if _, ok := f.links["wk0"]; ok {
+		t.Fatal("the half configured link is left for the next plans to keep down")
+	}
+
+	f.failing = ""
+	current, err := b.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Apply(diffWgConf(desired, current)); err != nil {
+		t.Fatal(err)
+	}
+	if !f.up["wk0"] || !b.hooksApplied {
+		t.Errorf("link up: %v, hooks applied: %v", f.up["wk0"], b.hooksApplied)
+	}
+}